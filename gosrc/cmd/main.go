@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,11 +12,15 @@ import (
 	"rdpalert/embedded"
 	"rdpalert/pushsdk"
 	"rdpalert/utils"
+	"strings"
+	"time"
 )
 
 const (
 	LOGFILE_NAME  = "rdpalert_running.log"
 	CONFJSON_NAME = "rdpalert_pushconf.json"
+	SPOOLDIR_NAME = "rdpalert_spool"
+	FLUSH_CMD     = "flush"
 )
 
 var (
@@ -24,6 +29,13 @@ var (
 )
 
 func main() {
+	// deferred first so it runs after every other teardown, os.Exit skips deferred calls
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 	// make sure log file is written to where program located, since CWD is SYSTEM32
 	curExecPath, err := os.Executable()
 	if err != nil {
@@ -60,7 +72,8 @@ func main() {
 	}
 	gLogger.Info("Current FilePath and Hostname got.")
 	// params handling
-	if len(os.Args) != 4 {
+	isFlushOnly := len(os.Args) == 2 && os.Args[1] == FLUSH_CMD
+	if len(os.Args) != 4 && !isFlushOnly {
 		printUsage()
 		gLogger.Critical("param length check:", ErrParamInvalid)
 	}
@@ -84,6 +97,23 @@ func main() {
 		gLogger.Critical("new pusher: ", err)
 	}
 	gLogger.Info("Pusher initialized.")
+	outbox, err := pushsdk.NewOutbox(filepath.Join(curWorkPath, SPOOLDIR_NAME), pushConf.Outbox)
+	if err != nil {
+		gLogger.Critical("new outbox: ", err)
+	}
+	pusher.AttachOutbox(outbox)
+	// whole run including outbox flush is bounded by delivery deadline
+	ctx, cancel := pushConf.DeliveryContext(context.Background())
+	defer cancel()
+	if isFlushOnly {
+		flushed, err := pusher.FlushOutbox(ctx)
+		if err != nil {
+			gLogger.Error("flush outbox: ", err)
+			exitCode = 1
+		}
+		gLogger.Info("Outbox flushed, entries delivered: ", flushed)
+		return
+	}
 	// build generalized push content
 	gpc, err := preparePushContent()
 	if err != nil {
@@ -92,16 +122,31 @@ func main() {
 	gLogger.Info("Push content prepared.")
 	pusher.StageGeneralPushContent(gpc)
 	gLogger.Info("Push content staged successfully.")
-	// transform and send out
-	err = pusher.SendPush()
+	// current alert goes before spooled ones, so it won't wait behind a long backlog
+	report, err := pusher.SendPush(ctx)
 	if err != nil {
-		gLogger.Critical("send push content:", err)
+		// failed ones are spooled in outbox and retried in next run,
+		// still exit with non-zero code so that task scheduler records the failure
+		if report != nil {
+			gLogger.Error("Partial push result: ", report.String())
+		}
+		gLogger.Error("send push content:", err)
+		exitCode = 1
+	} else {
+		gLogger.Info("Push content sent successfully: ", report.String())
 	}
-	gLogger.Info("Push content sent successfully.")
+	// deliver undelivered alerts from previous invocations within what is left of delivery deadline,
+	// it runs even if some provider failed above, or one broken provider would keep the spool from draining
+	flushed, err := pusher.FlushOutbox(ctx)
+	if err != nil {
+		gLogger.Error("flush outbox: ", err)
+	}
+	gLogger.Info("Outbox flushed, entries delivered: ", flushed)
 }
 
 func printUsage() {
 	_, _ = fmt.Fprintln(os.Stderr, "Usage: RDPAlarm.exe <Auth Domain> <Auth Username> <Auth IP>.")
+	_, _ = fmt.Fprintln(os.Stderr, "       RDPAlarm.exe flush")
 }
 
 func preparePushContent() (*pushsdk.GeneralPushContent, error) {
@@ -134,6 +179,16 @@ func preparePushContent() (*pushsdk.GeneralPushContent, error) {
 		Title:       notiTitle,
 		ShortTitle:  notiShort,
 		Description: notiBody,
+		Severity:    pushsdk.SeverityWarning,
+		EventType:   pushsdk.EventRDPLoginSuccess,
+		OccurredAt:  time.Now(),
+		Fields: []pushsdk.PushField{
+			{Name: pushsdk.FieldSourceIP, Value: args[3]},
+			{Name: pushsdk.FieldUser, Value: args[2]},
+			{Name: pushsdk.FieldDomain, Value: authDomain},
+			{Name: pushsdk.FieldHost, Value: hostname},
+			{Name: pushsdk.FieldHostIPs, Value: strings.Join(cIPs, ", ")},
+		},
 		ExtParams: map[string]any{
 			"copyDest": hostname,
 		},
@@ -0,0 +1,99 @@
+package pushsdk
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	BarkCipherAES128 = "AES128"
+	BarkCipherAES192 = "AES192"
+	BarkCipherAES256 = "AES256"
+
+	BarkCipherModeCBC = "CBC"
+	BarkCipherModeECB = "ECB"
+
+	barkIVChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var ErrBarkCipherKeyLength = errors.New("bark encryption key length mismatches algorithm")
+
+// barkKeyLengths is key length in bytes of each algorithm
+var barkKeyLengths = map[string]int{
+	BarkCipherAES128: 16,
+	BarkCipherAES192: 24,
+	BarkCipherAES256: 32,
+}
+
+// barkEncryptionParams is the same as encryption settings in Bark app, key and IV are plain text as the app takes,
+// check: https://bark.day.app/#/encryption
+type barkEncryptionParams struct {
+	Algorithm string `json:"algorithm" validate:"required,oneof=AES128 AES192 AES256"`
+	// Mode ECB is supported for compatibility only, it leaks patterns of plaintext
+	Mode string `json:"mode" validate:"required,oneof=CBC ECB"`
+	Key  string `json:"key" validate:"required"`
+	// IV is fixed IV for CBC, a random one is generated and sent along for each push if empty
+	IV string `json:"iv,omitempty" validate:"omitempty,len=16"`
+}
+
+func (bep *barkEncryptionParams) verify() error {
+	err := verifier.Struct(bep)
+	if err != nil {
+		return err
+	}
+	if len(bep.Key) != barkKeyLengths[bep.Algorithm] {
+		return fmt.Errorf("%w: %s requires %d bytes, got %d", ErrBarkCipherKeyLength, bep.Algorithm, barkKeyLengths[bep.Algorithm], len(bep.Key))
+	}
+	return nil
+}
+
+// encrypt pads plaintext with PKCS7 and returns base64 ciphertext with IV used, IV is empty for ECB
+func (bep *barkEncryptionParams) encrypt(plaintext []byte) (string, string, error) {
+	block, err := aes.NewCipher([]byte(bep.Key))
+	if err != nil {
+		return "", "", err
+	}
+	data := pkcs7Pad(plaintext, block.BlockSize())
+	out := make([]byte, len(data))
+	iv := ""
+	switch bep.Mode {
+	case BarkCipherModeECB:
+		for i := 0; i < len(data); i += block.BlockSize() {
+			block.Encrypt(out[i:i+block.BlockSize()], data[i:i+block.BlockSize()])
+		}
+	default:
+		iv = bep.IV
+		if iv == "" {
+			iv, err = randomBarkIV()
+			if err != nil {
+				return "", "", err
+			}
+		}
+		cipher.NewCBCEncrypter(block, []byte(iv)).CryptBlocks(out, data)
+	}
+	return base64.StdEncoding.EncodeToString(out), iv, nil
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	return append(bytes.Clone(data), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+}
+
+// randomBarkIV generates 16 alphanumeric chars, so it could be typed into the app if needed
+func randomBarkIV() (string, error) {
+	iv := make([]byte, aes.BlockSize)
+	for i := range iv {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(barkIVChars))))
+		if err != nil {
+			return "", err
+		}
+		iv[i] = barkIVChars[n.Int64()]
+	}
+	return string(iv), nil
+}
@@ -0,0 +1,164 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// decryptBark does what Bark app does with a ciphertext push
+func decryptBark(t *testing.T, enc *barkEncryptionParams, ciphertext string, iv string) []byte {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		t.Fatalf("ciphertext is not base64: %v", err)
+	}
+	block, err := aes.NewCipher([]byte(enc.Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		t.Fatalf("ciphertext length %d is not whole blocks", len(data))
+	}
+	plain := make([]byte, len(data))
+	switch enc.Mode {
+	case BarkCipherModeECB:
+		for i := 0; i < len(data); i += block.BlockSize() {
+			block.Decrypt(plain[i:i+block.BlockSize()], data[i:i+block.BlockSize()])
+		}
+	default:
+		cipher.NewCBCDecrypter(block, []byte(iv)).CryptBlocks(plain, data)
+	}
+	padLen := int(plain[len(plain)-1])
+	if padLen == 0 || padLen > block.BlockSize() || !bytes.Equal(plain[len(plain)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		t.Fatalf("bad PKCS7 padding of plaintext %q", plain)
+	}
+	return plain[:len(plain)-padLen]
+}
+
+func TestBarkCiphertext(t *testing.T) {
+	cases := []struct {
+		name string
+		enc  *barkEncryptionParams
+	}{
+		{"CBC with fixed IV", &barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: "0123456789abcdef", IV: "fedcba9876543210"}},
+		{"CBC with generated IV", &barkEncryptionParams{Algorithm: BarkCipherAES256, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 32)}},
+		{"ECB", &barkEncryptionParams{Algorithm: BarkCipherAES192, Mode: BarkCipherModeECB, Key: strings.Repeat("k", 24)}},
+	}
+	g := &GeneralPushContent{Title: "RDP Login - Success", ShortTitle: "alice from 10.0.0.8", Description: "From: 10.0.0.8"}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				_, _ = w.Write([]byte(`{"code":200,"message":"success","timestamp":1700000000}`))
+			}))
+			defer srv.Close()
+			ext := &barkPushProviderExtraParams{DeviceKeys: []string{"key1", "key2"}, Encryption: c.enc}
+			prv := &barkPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/push", ExtraParams: ext}
+			if err := prv.VerifyConfig(); err != nil {
+				t.Fatalf("verify config: %v", err)
+			}
+			spc, err := prv.TransformToSpecificPushContent(g)
+			if err != nil {
+				t.Fatalf("transform: %v", err)
+			}
+			if _, err := prv.SendPushContent(context.Background(), spc); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			keys := make([]string, 0, len(got))
+			for k := range got {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			wantKeys := []string{"ciphertext", "device_keys", "iv"}
+			if c.enc.Mode == BarkCipherModeECB {
+				wantKeys = []string{"ciphertext", "device_keys"}
+			}
+			if !slices.Equal(keys, wantKeys) {
+				t.Errorf("plain text fields = %v, want %v", keys, wantKeys)
+			}
+			if !reflect.DeepEqual(got["device_keys"], []any{"key1", "key2"}) {
+				t.Errorf("device_keys = %v", got["device_keys"])
+			}
+			iv, _ := got["iv"].(string)
+			switch {
+			case c.enc.Mode == BarkCipherModeCBC && c.enc.IV != "" && iv != c.enc.IV:
+				t.Errorf("iv = %q, want the configured one", iv)
+			case c.enc.Mode == BarkCipherModeCBC && len(iv) != aes.BlockSize:
+				t.Errorf("iv = %q, want a generated one of 16 bytes", iv)
+			}
+
+			ciphertext, _ := got["ciphertext"].(string)
+			var plain, want map[string]any
+			if err := json.Unmarshal(decryptBark(t, c.enc, ciphertext, iv), &plain); err != nil {
+				t.Fatalf("plaintext is not json: %v", err)
+			}
+			// the same content without encryption, less device keys
+			unencrypted, _ := (barkPushProvider{ExtraParams: &barkPushProviderExtraParams{DeviceKeys: ext.DeviceKeys}}).TransformToSpecificPushContent(g)
+			wantData, _ := unencrypted.ToBytes()
+			_ = json.Unmarshal(wantData, &want)
+			delete(want, "device_keys")
+			if !reflect.DeepEqual(plain, want) {
+				t.Errorf("plaintext = %v\nwant %v", plain, want)
+			}
+		})
+	}
+}
+
+func TestBarkGeneratedIVChanges(t *testing.T) {
+	enc := &barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: "0123456789abcdef"}
+	_, iv1, err := enc.encrypt([]byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, iv2, _ := enc.encrypt([]byte("{}"))
+	if iv1 == iv2 {
+		t.Errorf("the same IV %q is generated twice", iv1)
+	}
+}
+
+func TestBarkEncryptionVerify(t *testing.T) {
+	cases := []struct {
+		name  string
+		enc   barkEncryptionParams
+		valid bool
+		err   error
+	}{
+		{"AES128", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 16)}, true, nil},
+		{"AES192", barkEncryptionParams{Algorithm: BarkCipherAES192, Mode: BarkCipherModeECB, Key: strings.Repeat("k", 24)}, true, nil},
+		{"AES256 with IV", barkEncryptionParams{Algorithm: BarkCipherAES256, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 32), IV: strings.Repeat("i", 16)}, true, nil},
+		{"15 bytes key", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 15)}, false, ErrBarkCipherKeyLength},
+		{"17 bytes key", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 17)}, false, ErrBarkCipherKeyLength},
+		{"33 bytes key", barkEncryptionParams{Algorithm: BarkCipherAES256, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 33)}, false, ErrBarkCipherKeyLength},
+		{"key of another algorithm", barkEncryptionParams{Algorithm: BarkCipherAES192, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 16)}, false, ErrBarkCipherKeyLength},
+		{"15 bytes IV", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 16), IV: strings.Repeat("i", 15)}, false, nil},
+		{"17 bytes IV", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: BarkCipherModeCBC, Key: strings.Repeat("k", 16), IV: strings.Repeat("i", 17)}, false, nil},
+		{"unknown mode", barkEncryptionParams{Algorithm: BarkCipherAES128, Mode: "GCM", Key: strings.Repeat("k", 16)}, false, nil},
+	}
+	for _, c := range cases {
+		enc := c.enc
+		prv := barkPushProvider{
+			ProviderServerURL: "https://api.day.app/push",
+			ExtraParams:       &barkPushProviderExtraParams{DeviceKeys: []string{"key1"}, Encryption: &enc},
+		}
+		err := prv.VerifyConfig()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: verify config = %v, want valid %v", c.name, err, c.valid)
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: error = %v, want %v", c.name, err, c.err)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package pushsdk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"net/http"
 	"rdpalert/utils"
+	"strings"
 )
 
 // barkPushContent is an instance of https://github.com/Finb/bark-server/blob/master/docs/API_V2.md
@@ -32,11 +37,30 @@ type barkPushContent struct {
 	IsArchive string `json:"isArchive,omitempty" validate:"omitempty,oneof='0' '1'"`
 	// Jump to URL when clicked
 	URL string `json:"url,omitempty" validate:"omitempty,url"`
+	// Call must be "1" to ring repeatedly for 30 seconds
+	Call string `json:"call,omitempty" validate:"omitempty,oneof='1'"`
+	// Volume is for critical level only, from 0 to 10
+	Volume *int `json:"volume,omitempty" validate:"omitempty,gte=0,lte=10"`
+	// ID replaces notification with the same ID
+	ID string `json:"id,omitempty"`
+	// Action "none" makes tapping notification do nothing
+	Action string `json:"action,omitempty" validate:"omitempty,oneof=none"`
 
+	// eventOverrides are applied by EventType of the alert
+	eventOverrides map[string]*barkNotificationOptions
+	// encryption is set when ciphertext mode is enabled
+	encryption *barkEncryptionParams
 	// provider info
 	providerName PushProvider
 }
 
+// barkEncryptedPushContent is what is actually sent in ciphertext mode, device keys are left in plain text for routing
+type barkEncryptedPushContent struct {
+	Ciphertext string   `json:"ciphertext"`
+	IV         string   `json:"iv,omitempty"`
+	DeviceKeys []string `json:"device_keys"`
+}
+
 func (bpct *barkPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
 	copyVal, exist1 := g.ExtParams["copyDest"]
 	if exist1 {
@@ -45,11 +69,70 @@ func (bpct *barkPushContent) FromGeneral(g *GeneralPushContent) (PushContent, er
 	bpct.Title = g.Title
 	bpct.SubTitle = g.ShortTitle
 	bpct.Body = g.Description
+	if o, ok := bpct.eventOverrides[g.EventType]; ok {
+		bpct.applyOptions(o)
+	}
 	return bpct, nil
 }
 
+// applyOptions overwrites content with options set, unset options are left as-is
+func (bpct *barkPushContent) applyOptions(o *barkNotificationOptions) {
+	if o == nil {
+		return
+	}
+	if o.IOSNotificationLevel != "" {
+		bpct.Level = o.IOSNotificationLevel
+	}
+	if o.NotificationGroup != "" {
+		bpct.Group = o.NotificationGroup
+	}
+	if o.Sound != "" {
+		bpct.Sound = o.Sound
+	}
+	if o.Icon != "" {
+		bpct.Icon = o.Icon
+	}
+	if o.Badge != 0 {
+		bpct.Badge = o.Badge
+	}
+	if o.URL != "" {
+		bpct.URL = o.URL
+	}
+	if o.Call {
+		bpct.Call = "1"
+	}
+	if o.Volume != nil {
+		bpct.Volume = o.Volume
+	}
+	if o.ID != "" {
+		bpct.ID = o.ID
+	}
+	if o.Action != "" {
+		bpct.Action = o.Action
+	}
+}
+
+// ToBytes serializes content, and encrypts everything except device keys in ciphertext mode
 func (bpct *barkPushContent) ToBytes() ([]byte, error) {
-	return json.Marshal(bpct)
+	if bpct.encryption == nil {
+		return json.Marshal(bpct)
+	}
+	plain := *bpct
+	plain.DeviceKey = ""
+	plain.DeviceKeys = nil
+	plainData, err := json.Marshal(&plain)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, iv, err := bpct.encryption.encrypt(plainData)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&barkEncryptedPushContent{
+		Ciphertext: ciphertext,
+		IV:         iv,
+		DeviceKeys: bpct.DeviceKeys,
+	})
 }
 
 func (bpct *barkPushContent) Init() {
@@ -71,16 +154,24 @@ func (bpct *barkPushContent) SetPushProvider() {
 
 func (bpct *barkPushContent) AcceptExtParamSettings(d any) {
 	d1 := d.(*barkPushProviderExtraParams)
-	if d1.IOSNotificationLevel != "" {
-		bpct.Level = d1.IOSNotificationLevel
-	}
-	if d1.NotificationGroup != "" {
-		bpct.Group = d1.NotificationGroup
-	}
+	bpct.applyOptions(&d1.barkNotificationOptions)
+	bpct.eventOverrides = d1.EventOverrides
 	bpct.DeviceKeys = d1.DeviceKeys
+	bpct.encryption = d1.Encryption
+}
+
+func init() {
+	err := verifier.RegisterValidation("barksound", func(fl validator.FieldLevel) bool {
+		return barkAlertSounds[barkAlertSound(fl.Field().String())]
+	})
+	if err != nil {
+		panic(err)
+	}
+	MustRegisterProvider(BarkForiOS, func() PushProviderImpl { return &barkPushProvider{} })
 }
 
 type barkPushProvider struct {
+	ProviderCommon
 	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
 	ExtraParams       *barkPushProviderExtraParams `json:"extParams" validate:"omitempty"`
 }
@@ -94,6 +185,9 @@ func (b barkPushProvider) VerifyConfig() error {
 	if err2 != nil {
 		return err2
 	}
+	if b.ExtraParams.Encryption != nil {
+		return b.ExtraParams.Encryption.verify()
+	}
 	return nil
 }
 
@@ -104,7 +198,7 @@ func (b barkPushProvider) TransformToSpecificPushContent(g *GeneralPushContent)
 	return bpct.FromGeneral(g)
 }
 
-func (b barkPushProvider) SendPushContent(p PushContent) (*PushResponse, error) {
+func (b barkPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
 	gLogger, err := utils.GetLoggerInstance()
 	if err != nil {
 		return nil, err
@@ -114,21 +208,79 @@ func (b barkPushProvider) SendPushContent(p PushContent) (*PushResponse, error)
 	if err != nil {
 		return nil, err
 	}
-	respData, _, err := SendHttpPostJSON(b.ProviderServerURL, body)
+	respData, statusCode, err := b.PostJSON(ctx, BarkForiOS, b.ProviderServerURL, body)
+	if err != nil {
+		return nil, parseBarkError(err)
+	}
 	pushResp := &PushResponse{}
 	err = json.Unmarshal(respData, pushResp)
 	if err != nil {
 		return nil, err
 	}
 	gLogger.Info("pushResp: ", pushResp.String())
+	if pushResp.Code == 0 {
+		pushResp.Code = statusCode
+	}
+	// bark server could also reply error code with HTTP 200
+	if pushResp.Code != http.StatusOK {
+		return pushResp, newBarkError(pushResp.Code, pushResp.Message, nil)
+	}
 	return pushResp, nil
 }
 
+// parseBarkError turns error response into *ProviderError, bark server replies error in the same format
+// as success with status code other than 200
+func parseBarkError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	pushResp := &PushResponse{}
+	if json.Unmarshal(statusErr.Body, pushResp) != nil {
+		return newBarkError(statusErr.StatusCode, truncateString(strings.TrimSpace(string(statusErr.Body)), 200), err)
+	}
+	if pushResp.Code == 0 {
+		pushResp.Code = statusErr.StatusCode
+	}
+	return newBarkError(pushResp.Code, pushResp.Message, err)
+}
+
+// newBarkError classifies error replied by bark server, which puts APNs reason into message if APNs rejects it,
+// check: https://github.com/Finb/bark-server/blob/master/route_push.go
+func newBarkError(code int, message string, err error) *ProviderError {
+	kind := errorKindByStatus(code)
+	for _, v := range []string{"device token", "device key", "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic"} {
+		if strings.Contains(message, v) {
+			kind = ErrInvalidDeviceKey
+			break
+		}
+	}
+	return &ProviderError{Provider: BarkForiOS, Kind: kind, Code: code, Message: message, Err: err}
+}
+
 type barkPushProviderExtraParams struct {
 	// Bark for ios only
+	DeviceKeys []string `json:"deviceKeys" validate:"required"`
+	barkNotificationOptions
+	// EventOverrides are applied on top of options above by GeneralPushContent.EventType, e.g. rdpLoginSuccess
+	EventOverrides map[string]*barkNotificationOptions `json:"eventOverrides,omitempty" validate:"omitempty,dive,keys,required,endkeys,required"`
+	// Encryption enables ciphertext mode, must match encryption settings in Bark app
+	Encryption *barkEncryptionParams `json:"encryption,omitempty" validate:"omitempty"`
+}
+
+// barkNotificationOptions are notification settings which could be overridden per event
+type barkNotificationOptions struct {
 	NotificationGroup    string                   `json:"notificationGrp,omitempty" validate:"omitempty"`
-	DeviceKeys           []string                 `json:"deviceKeys" validate:"required"`
 	IOSNotificationLevel barkiOSNotificationLevel `json:"iOSNotificationLvl,omitempty" validate:"omitempty,oneof='active' 'passive' 'timeSensitive' 'critical'"`
+	Sound                barkAlertSound           `json:"sound,omitempty" validate:"omitempty,barksound"`
+	Icon                 string                   `json:"icon,omitempty" validate:"omitempty,url"`
+	Badge                int                      `json:"badge,omitempty" validate:"omitempty,gt=0"`
+	URL                  string                   `json:"url,omitempty" validate:"omitempty,url"`
+	Call                 bool                     `json:"call,omitempty"`
+	// Volume takes effect with critical level only
+	Volume *int   `json:"volume,omitempty" validate:"omitempty,gte=0,lte=10"`
+	ID     string `json:"id,omitempty" validate:"omitempty"`
+	Action string `json:"action,omitempty" validate:"omitempty,oneof=none"`
 }
 
 type barkiOSNotificationLevel string
@@ -176,3 +328,12 @@ const (
 	Typewriters        barkAlertSound = "typewriters.caf"
 	Update             barkAlertSound = "update.caf"
 )
+
+// barkAlertSounds is the set of sounds built in Bark app
+var barkAlertSounds = map[barkAlertSound]bool{
+	Alarm: true, Anticipate: true, Bell: true, Birdsong: true, Bloom: true, Calypso: true, Chime: true, Choo: true,
+	Descent: true, Electronic: true, Fanfare: true, Glass: true, Gotosleep: true, Healthnotification: true, Horn: true,
+	Ladder: true, Mailsent: true, Minuet: true, Multiwayinvitation: true, Newmail: true, Newsflash: true, Noir: true,
+	Paymentsuccess: true, Shake: true, Sherwoodforest: true, Silence: true, Spell: true, Suspense: true, Telegraph: true,
+	Tiptoes: true, Typewriters: true, Update: true,
+}
@@ -0,0 +1,81 @@
+package pushsdk
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func newTestBark(t *testing.T, extParams string) *barkPushProvider {
+	t.Helper()
+	prv := &barkPushProvider{}
+	err := json.Unmarshal([]byte(`{"serverURL": "https://api.day.app/push", "extParams": `+extParams+`}`), prv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prv
+}
+
+func TestBarkVerifyConfig(t *testing.T) {
+	cases := []struct {
+		name      string
+		extParams string
+		valid     bool
+	}{
+		{"defaults", `{"deviceKeys": ["key1"]}`, true},
+		{"options", `{"deviceKeys": ["key1"], "sound": "alarm.caf", "iOSNotificationLvl": "critical", "volume": 10}`, true},
+		{"unknown sound", `{"deviceKeys": ["key1"], "sound": "siren.caf"}`, false},
+		{"sound without extension", `{"deviceKeys": ["key1"], "sound": "alarm"}`, false},
+		{"unknown level", `{"deviceKeys": ["key1"], "iOSNotificationLvl": "urgent"}`, false},
+		{"volume out of range", `{"deviceKeys": ["key1"], "volume": 11}`, false},
+		{"override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": {"sound": "minuet.caf", "iOSNotificationLvl": "timeSensitive", "volume": 0}}}`, true},
+		{"unknown sound in override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": {"sound": "siren.caf"}}}`, false},
+		{"unknown level in override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": {"iOSNotificationLvl": "urgent"}}}`, false},
+		{"negative volume in override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": {"volume": -1}}}`, false},
+		{"volume out of range in override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": {"volume": 11}}}`, false},
+		{"empty event type", `{"deviceKeys": ["key1"], "eventOverrides": {"": {"sound": "minuet.caf"}}}`, false},
+		{"null override", `{"deviceKeys": ["key1"], "eventOverrides": {"rdpLoginSuccess": null}}`, false},
+	}
+	for _, c := range cases {
+		err := newTestBark(t, c.extParams).VerifyConfig()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: verify config = %v, want valid %v", c.name, err, c.valid)
+		}
+	}
+}
+
+func TestBarkEventOverrides(t *testing.T) {
+	prv := newTestBark(t, `{
+		"deviceKeys": ["key1"],
+		"sound": "bell.caf",
+		"notificationGrp": "RdpAlert",
+		"eventOverrides": {
+			"rdpLoginSuccess": {"sound": "alarm.caf", "iOSNotificationLvl": "critical", "volume": 8},
+			"rdpLoginFailure": {"sound": "minuet.caf", "call": true}
+		}
+	}`)
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	transform := func(eventType string) *barkPushContent {
+		spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login", Description: "alice", EventType: eventType})
+		if err != nil {
+			t.Fatalf("transform: %v", err)
+		}
+		return spc.(*barkPushContent)
+	}
+
+	got := transform(EventRDPLoginSuccess)
+	if got.Sound != Alarm || got.Level != CriticalNotification || got.Volume == nil || *got.Volume != 8 || got.Call != "" {
+		t.Errorf("matching override: sound %s level %s volume %v call %q", got.Sound, got.Level, got.Volume, got.Call)
+	}
+	if got.Group != "RdpAlert" {
+		t.Errorf("option not overridden should be kept, group = %s", got.Group)
+	}
+
+	for _, eventType := range []string{"", "logoff"} {
+		got = transform(eventType)
+		if got.Sound != Bell || got.Level != ActiveNotification || got.Volume != nil || got.Call != "" || got.Group != "RdpAlert" {
+			t.Errorf("event %q: sound %s level %s volume %v call %q group %s", eventType, got.Sound, got.Level, got.Volume, got.Call, got.Group)
+		}
+	}
+}
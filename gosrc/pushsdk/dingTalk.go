@@ -0,0 +1,252 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DingTalkFormatMarkdown   = "markdown"
+	DingTalkFormatActionCard = "actionCard"
+
+	// dingTalkErrCodeTooFast is returned when robot sends more than 20 messages per minute
+	dingTalkErrCodeTooFast = 130101
+	// dingTalkErrCodeTokenNotExist is returned when robot is removed or access_token is wrong
+	dingTalkErrCodeTokenNotExist = 300001
+	// dingTalkErrCodeSecurity is returned when message fails security settings, i.e. sign, keywords or IP whitelist
+	dingTalkErrCodeSecurity = 310000
+)
+
+// dingTalkErrCodeKinds classifies errcode of robot response
+var dingTalkErrCodeKinds = map[int]error{
+	dingTalkErrCodeTooFast:       ErrRateLimited,
+	dingTalkErrCodeTokenNotExist: ErrInvalidDeviceKey,
+	dingTalkErrCodeSecurity:      ErrAuthFailed,
+}
+
+// dingTalkPushContent is a robot message, check: https://open.dingtalk.com/document/orgapp/custom-bot-send-message-type
+type dingTalkPushContent struct {
+	MsgType    string              `json:"msgtype" validate:"required"`
+	Markdown   *dingTalkMarkdown   `json:"markdown,omitempty"`
+	ActionCard *dingTalkActionCard `json:"actionCard,omitempty"`
+	At         *dingTalkAt         `json:"at,omitempty"`
+
+	actionTitle string
+	actionURL   string
+	// provider info
+	providerName PushProvider
+}
+
+type dingTalkMarkdown struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+type dingTalkActionCard struct {
+	Title          string `json:"title"`
+	Text           string `json:"text"`
+	SingleTitle    string `json:"singleTitle,omitempty"`
+	SingleURL      string `json:"singleURL,omitempty"`
+	BtnOrientation string `json:"btnOrientation,omitempty"`
+}
+
+type dingTalkAt struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	IsAtAll   bool     `json:"isAtAll,omitempty"`
+}
+
+func (dpc *dingTalkPushContent) Init() {
+	dpc.MsgType = DingTalkFormatMarkdown
+	dpc.SetPushProvider()
+}
+
+func (dpc *dingTalkPushContent) Provider() PushProvider {
+	return dpc.providerName
+}
+
+func (dpc *dingTalkPushContent) SetPushProvider() {
+	dpc.providerName = DingTalk
+}
+
+func (dpc *dingTalkPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*dingTalkPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Format != "" {
+		dpc.MsgType = d1.Format
+	}
+	if len(d1.AtMobiles) != 0 || d1.AtAll {
+		dpc.At = &dingTalkAt{AtMobiles: d1.AtMobiles, IsAtAll: d1.AtAll}
+	}
+	dpc.actionTitle = d1.ActionTitle
+	dpc.actionURL = d1.ActionURL
+}
+
+// FromGeneral renders markdown text, mentioned mobiles must appear in text to be highlighted
+func (dpc *dingTalkPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	text := "#### " + g.Title + "\n\n" + robotMarkdownBody(g)
+	if dpc.At != nil {
+		for _, v := range dpc.At.AtMobiles {
+			text += " @" + v
+		}
+	}
+	switch dpc.MsgType {
+	case DingTalkFormatActionCard:
+		dpc.ActionCard = &dingTalkActionCard{
+			Title:          g.Title,
+			Text:           text,
+			SingleTitle:    dpc.actionTitle,
+			SingleURL:      dpc.actionURL,
+			BtnOrientation: "0",
+		}
+	default:
+		dpc.Markdown = &dingTalkMarkdown{Title: g.Title, Text: text}
+	}
+	return dpc, nil
+}
+
+func (dpc *dingTalkPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(dpc)
+}
+
+// robotMarkdownBody renders login fields for the markdown subset of DingTalk and WeCom,
+// neither of them honors backslash escaping, so values are written as-is on separate paragraphs
+func robotMarkdownBody(g *GeneralPushContent) string {
+	if len(g.Fields) == 0 {
+		return strings.ReplaceAll(strings.TrimSpace(g.Description), "\n", "\n\n")
+	}
+	lines := make([]string, 0, len(g.Fields))
+	for _, v := range g.Fields {
+		lines = append(lines, fmt.Sprintf("**%s**: %s", v.Name, v.Value))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+func init() {
+	MustRegisterProvider(DingTalk, func() PushProviderImpl { return &dingTalkPushProvider{} })
+}
+
+type dingTalkPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is robot webhook URL, https://oapi.dingtalk.com/robot/send?access_token=xxx
+	ProviderServerURL string                           `json:"serverURL" validate:"url,required"`
+	ExtraParams       *dingTalkPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (d dingTalkPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(d)
+	if err1 != nil {
+		return err1
+	}
+	if d.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(d.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (d dingTalkPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	dpc := &dingTalkPushContent{}
+	dpc.Init()
+	dpc.AcceptExtParamSettings(d.ExtraParams)
+	return dpc.FromGeneral(g)
+}
+
+func (d dingTalkPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*dingTalkPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	reqURL, err := url.Parse(d.ProviderServerURL)
+	if err != nil {
+		return nil, err
+	}
+	secrets := []string{reqURL.RawQuery}
+	if d.ExtraParams != nil && d.ExtraParams.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		query := reqURL.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", signDingTalk(timestamp, d.ExtraParams.Secret))
+		reqURL.RawQuery = query.Encode()
+		secrets = append(secrets, reqURL.RawQuery)
+	}
+	respData, _, err := d.SendRequest(ctx, DingTalk, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     reqURL.String(),
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseStatusError(DingTalk, err)
+	}
+	return parseRobotResponse(DingTalk, respData, dingTalkErrCodeKinds)
+}
+
+// signDingTalk signs timestamp in milliseconds, check: https://open.dingtalk.com/document/robots/customize-robot-security-settings
+func signDingTalk(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// robotPushResponse is replied by DingTalk and WeCom robots, HTTP status is 200 even on error
+type robotPushResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// parseRobotResponse puts errcode into PushResponse, which is also returned on error for inspection,
+// errcode other than 0 is returned as *ProviderError classified by codeKinds
+func parseRobotResponse(provider PushProvider, respData []byte, codeKinds map[int]error) (*PushResponse, error) {
+	rpr := &robotPushResponse{}
+	err := json.Unmarshal(respData, rpr)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PushResponse{
+		Code:      rpr.ErrCode,
+		Message:   rpr.ErrMsg,
+		Timestamp: time.Now().Unix(),
+	}
+	if rpr.ErrCode == 0 {
+		return resp, nil
+	}
+	return resp, newCodeError(provider, rpr.ErrCode, rpr.ErrMsg, codeKinds)
+}
+
+// newCodeError makes *ProviderError of service error code replied with HTTP 200,
+// code missing in codeKinds is ErrHttpRequestFailed, since it's not an HTTP status
+func newCodeError(provider PushProvider, code int, message string, codeKinds map[int]error) *ProviderError {
+	kind, ok := codeKinds[code]
+	if !ok {
+		kind = ErrHttpRequestFailed
+	}
+	return &ProviderError{Provider: provider, Kind: kind, Code: code, Message: message}
+}
+
+type dingTalkPushProviderExtraParams struct {
+	// Secret enables signing, which starts with SEC in robot security settings
+	Secret string `json:"secret,omitempty" validate:"omitempty,startswith=SEC"`
+	// Format is markdown by default
+	Format    string   `json:"format,omitempty" validate:"omitempty,oneof=markdown actionCard"`
+	AtMobiles []string `json:"atMobiles,omitempty" validate:"omitempty,dive,numeric"`
+	AtAll     bool     `json:"atAll,omitempty"`
+	// ActionURL and ActionTitle make the button of actionCard
+	ActionURL   string `json:"actionURL,omitempty" validate:"required_if=Format actionCard,omitempty,url"`
+	ActionTitle string `json:"actionTitle,omitempty" validate:"required_with=ActionURL"`
+}
@@ -0,0 +1,94 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignDingTalk(t *testing.T) {
+	// secret is the HMAC key, message is timestamp in milliseconds and secret joined by a newline
+	got := signDingTalk("1700000000000", "SECa1b2c3d4e5f6")
+	if want := "oJQpdCHfADjAvXpklWV1PzUEobyTlyhOkQWGlZgxWPE="; got != want {
+		t.Errorf("sign = %s, want %s", got, want)
+	}
+}
+
+// robotReply serves a robot webhook replying body with HTTP 200, the last request URL is kept in gotURL
+func robotReply(body string, gotURL *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gotURL != nil {
+			*gotURL = r.URL.String()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestDingTalkSignedRequest(t *testing.T) {
+	var gotURL string
+	srv := robotReply(`{"errcode":0,"errmsg":"ok"}`, &gotURL)
+	defer srv.Close()
+	prv := dingTalkPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/robot/send?access_token=abc",
+		ExtraParams: &dingTalkPushProviderExtraParams{Secret: "SECa1b2c3d4e5f6"}}
+	spc, _ := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice"})
+	if _, err := prv.SendPushContent(context.Background(), spc); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, gotURL, nil)
+	query := req.URL.Query()
+	if query.Get("access_token") != "abc" || query.Get("timestamp") == "" {
+		t.Fatalf("query = %s", req.URL.RawQuery)
+	}
+	if want := signDingTalk(query.Get("timestamp"), "SECa1b2c3d4e5f6"); query.Get("sign") != want {
+		t.Errorf("sign = %s, want %s", query.Get("sign"), want)
+	}
+}
+
+func TestRobotErrCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider PushProvider
+		body     string
+		wantKind error
+		wantCode int
+	}{
+		{"dingtalk too fast", DingTalk, `{"errcode":130101,"errmsg":"send too fast, exceed 20 times per minute"}`, ErrRateLimited, 130101},
+		{"dingtalk sign mismatch", DingTalk, `{"errcode":310000,"errmsg":"sign not match"}`, ErrAuthFailed, 310000},
+		{"dingtalk token not exist", DingTalk, `{"errcode":300001,"errmsg":"token is not exist"}`, ErrInvalidDeviceKey, 300001},
+		{"dingtalk unknown code", DingTalk, `{"errcode":40035,"errmsg":"missing parameter"}`, ErrHttpRequestFailed, 40035},
+		{"wecom freq out of limit", WeCom, `{"errcode":45009,"errmsg":"api freq out of limit"}`, ErrRateLimited, 45009},
+		{"wecom invalid webhook", WeCom, `{"errcode":93000,"errmsg":"invalid webhook url"}`, ErrInvalidDeviceKey, 93000},
+		{"wecom unknown code", WeCom, `{"errcode":40058,"errmsg":"markdown.content exceed max length 4096"}`, ErrHttpRequestFailed, 40058},
+	}
+	g := &GeneralPushContent{Title: "RDP Login - Success", Description: "alice"}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := robotReply(c.body, nil)
+			defer srv.Close()
+			var prv PushProviderImpl = dingTalkPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/robot/send?access_token=abc"}
+			if c.provider == WeCom {
+				prv = weComPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/cgi-bin/webhook/send?key=abc"}
+			}
+			spc, err := prv.TransformToSpecificPushContent(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := prv.SendPushContent(context.Background(), spc)
+			var provErr *ProviderError
+			if !errors.As(err, &provErr) {
+				t.Fatalf("want *ProviderError, got %T: %v", err, err)
+			}
+			if provErr.Provider != c.provider || provErr.Code != c.wantCode || !errors.Is(err, c.wantKind) {
+				t.Errorf("got %s code %d %v, want %s code %d %v", provErr.Provider, provErr.Code, provErr.Kind, c.provider, c.wantCode, c.wantKind)
+			}
+			if provErr.Temporary() != (c.wantKind == ErrRateLimited) {
+				t.Errorf("temporary = %v", provErr.Temporary())
+			}
+			if resp == nil || resp.Code != c.wantCode {
+				t.Errorf("push response = %v, want the replied one", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// discordMarkdownEscaper keeps login info from being rendered as markdown, brackets are escaped
+// so that a crafted user name can't make a masked link
+var discordMarkdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`, "[", `\[`, "]", `\]`,
+)
+
+// discordPushContent is an instance of https://discord.com/developers/docs/resources/webhook#execute-webhook
+type discordPushContent struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds" validate:"required,min=1"`
+
+	// provider info
+	providerName PushProvider
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+func (dpc *discordPushContent) Init() {
+	dpc.SetPushProvider()
+}
+
+func (dpc *discordPushContent) Provider() PushProvider {
+	return dpc.providerName
+}
+
+func (dpc *discordPushContent) SetPushProvider() {
+	dpc.providerName = Discord
+}
+
+func (dpc *discordPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*discordPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	dpc.Username = d1.Username
+	dpc.AvatarURL = d1.AvatarURL
+}
+
+// FromGeneral builds a single embed, structured fields are preferred over Description
+func (dpc *discordPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	embed := discordEmbed{
+		Title:     g.Title,
+		Color:     g.SeverityOrDefault().Color(),
+		Timestamp: g.OccurredAtOrNow().Format(time.RFC3339),
+	}
+	if len(g.Fields) == 0 {
+		embed.Description = discordMarkdownEscaper.Replace(g.Description)
+	} else {
+		embed.Description = discordMarkdownEscaper.Replace(g.ShortTitle)
+		for _, v := range g.Fields {
+			embed.Fields = append(embed.Fields, discordEmbedField{
+				Name:   v.Name,
+				Value:  discordMarkdownEscaper.Replace(v.Value),
+				Inline: v.Name != FieldHostIPs,
+			})
+		}
+	}
+	if host := g.Field(FieldHost); host != "" {
+		embed.Footer = &discordEmbedFooter{Text: host}
+	}
+	dpc.Embeds = []discordEmbed{embed}
+	return dpc, nil
+}
+
+func (dpc *discordPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(dpc)
+}
+
+func init() {
+	MustRegisterProvider(Discord, func() PushProviderImpl { return &discordPushProvider{} })
+}
+
+type discordPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is the webhook URL copied from channel settings
+	ProviderServerURL string                          `json:"serverURL" validate:"url,required"`
+	ExtraParams       *discordPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (d discordPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(d)
+	if err1 != nil {
+		return err1
+	}
+	if d.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(d.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (d discordPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	dpc := &discordPushContent{}
+	dpc.Init()
+	dpc.AcceptExtParamSettings(d.ExtraParams)
+	return dpc.FromGeneral(g)
+}
+
+func (d discordPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*discordPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	// wait=true makes discord reply the created message instead of 204
+	reqURL, err := url.Parse(d.ProviderServerURL)
+	if err != nil {
+		return nil, err
+	}
+	query := reqURL.Query()
+	query.Set("wait", "true")
+	reqURL.RawQuery = query.Encode()
+	respData, statusCode, err := d.SendRequest(ctx, Discord, &HTTPRequest{
+		Method:     http.MethodPost,
+		URL:        reqURL.String(),
+		Header:     http.Header{"Content-Type": {postJSONContentType}},
+		Body:       body,
+		RetryAfter: parseDiscordRetryAfter,
+		Secrets:    []string{reqURL.Path},
+	})
+	if err != nil {
+		return nil, parseDiscordError(err)
+	}
+	dpr := &discordPushResponse{}
+	err = json.Unmarshal(respData, dpr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("MessageID: %s, ChannelID: %s", dpr.ID, dpr.ChannelID),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+type discordPushResponse struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// discordErrorResponse is returned with 4xx, check: https://discord.com/developers/docs/topics/rate-limits
+type discordErrorResponse struct {
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
+
+// parseDiscordRetryAfter prefers retry_after in body, which is more precise than header
+func parseDiscordRetryAfter(header http.Header, body []byte) time.Duration {
+	der := &discordErrorResponse{}
+	if json.Unmarshal(body, der) == nil && der.RetryAfter > 0 {
+		return time.Duration(der.RetryAfter * float64(time.Second))
+	}
+	return parseRetryAfter(header.Get("Retry-After"))
+}
+
+// parseDiscordError turns error response into *ProviderError, JSON error code is preferred over HTTP status,
+// check: https://discord.com/developers/docs/topics/opcodes-and-status-codes#json
+func parseDiscordError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	der := &discordErrorResponse{}
+	_ = json.Unmarshal(statusErr.Body, der)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch der.Code {
+	case 10015:
+		// unknown webhook, it's deleted
+		kind = ErrInvalidDeviceKey
+	case 50027:
+		// invalid webhook token
+		kind = ErrAuthFailed
+	}
+	code, msg := der.Code, der.Message
+	if code == 0 {
+		code = statusErr.StatusCode
+	}
+	if statusErr.StatusCode == http.StatusTooManyRequests {
+		msg = fmt.Sprintf("%s retry after %s, global: %v", msg, statusErr.RetryAfter, der.Global)
+	}
+	return &ProviderError{
+		Provider: Discord,
+		Kind:     kind,
+		Code:     code,
+		Message:  strings.TrimSpace(msg),
+		Err:      err,
+	}
+}
+
+type discordPushProviderExtraParams struct {
+	// Username and AvatarURL override default of the webhook
+	Username  string `json:"username,omitempty" validate:"omitempty,max=80"`
+	AvatarURL string `json:"avatarURL,omitempty" validate:"omitempty,url"`
+}
@@ -0,0 +1,36 @@
+package pushsdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiscordEmbed(t *testing.T) {
+	occurredAt := time.Date(2026, 3, 1, 8, 30, 0, 0, time.FixedZone("CST", 8*3600))
+	prv := discordPushProvider{}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+		Title:      "RDP Login - Success",
+		ShortTitle: "[click me](https://evil.example) from 10.0.0.8",
+		OccurredAt: occurredAt,
+		Fields: []PushField{
+			{Name: FieldUser, Value: "[admin](https://evil.example)"},
+			{Name: FieldHost, Value: "WIN_SRV*01"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	embed := spc.(*discordPushContent).Embeds[0]
+	if embed.Timestamp != "2026-03-01T08:30:00+08:00" {
+		t.Errorf("timestamp = %q, want time the login occurred", embed.Timestamp)
+	}
+	if want := `\[click me\](https://evil.example) from 10.0.0.8`; embed.Description != want {
+		t.Errorf("description = %q, want %q", embed.Description, want)
+	}
+	if want := `\[admin\](https://evil.example)`; embed.Fields[0].Value != want {
+		t.Errorf("user field = %q, want %q", embed.Fields[0].Value, want)
+	}
+	if want := `WIN\_SRV\*01`; embed.Fields[1].Value != want {
+		t.Errorf("host field = %q, want %q", embed.Fields[1].Value, want)
+	}
+}
@@ -0,0 +1,221 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultElasticsearchIndex = "rdpalert"
+	ndjsonContentType         = "application/x-ndjson"
+)
+
+// elasticsearchPushContent is a single create action of bulk API, event_id is used as document ID,
+// so a retried push won't index the same alert twice
+type elasticsearchPushContent struct {
+	Index    string
+	Pipeline string
+	Doc      *alertEvent `validate:"required"`
+
+	// provider info
+	providerName PushProvider
+}
+
+func (epc *elasticsearchPushContent) Init() {
+	epc.SetPushProvider()
+	epc.Index = defaultElasticsearchIndex
+}
+
+func (epc *elasticsearchPushContent) Provider() PushProvider {
+	return epc.providerName
+}
+
+func (epc *elasticsearchPushContent) SetPushProvider() {
+	epc.providerName = Elasticsearch
+}
+
+func (epc *elasticsearchPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*elasticsearchPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Index != "" {
+		epc.Index = d1.Index
+	}
+	epc.Pipeline = d1.Pipeline
+}
+
+func (epc *elasticsearchPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	epc.Doc = newAlertEvent(g)
+	return epc, nil
+}
+
+// ToBytes renders action and source lines, check: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+func (epc *elasticsearchPushContent) ToBytes() ([]byte, error) {
+	action := map[string]map[string]string{
+		"create": {"_index": epc.Index, "_id": epc.Doc.ID},
+	}
+	if epc.Pipeline != "" {
+		action["create"]["pipeline"] = epc.Pipeline
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	// Encode ends every document with newline, which is exactly what ndjson wants
+	err := enc.Encode(action)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Encode(epc.Doc)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	MustRegisterProvider(Elasticsearch, func() PushProviderImpl { return &elasticsearchPushProvider{} })
+}
+
+type elasticsearchPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is base URL of cluster, https://es.corp.local:9200, /_bulk is appended
+	ProviderServerURL string                                `json:"serverURL" validate:"url,required"`
+	ExtraParams       *elasticsearchPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (e elasticsearchPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(e)
+	if err1 != nil {
+		return err1
+	}
+	if e.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(e.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (e elasticsearchPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	epc := &elasticsearchPushContent{}
+	epc.Init()
+	epc.AcceptExtParamSettings(e.ExtraParams)
+	return epc.FromGeneral(g)
+}
+
+func (e elasticsearchPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*elasticsearchPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Content-Type": {ndjsonContentType}}
+	secrets := make([]string, 0, 1)
+	if e.ExtraParams != nil {
+		switch {
+		case e.ExtraParams.APIKey != "":
+			header.Set("Authorization", "ApiKey "+e.ExtraParams.APIKey)
+			secrets = append(secrets, e.ExtraParams.APIKey)
+		case e.ExtraParams.Username != "":
+			header.Set("Authorization", basicAuthHeader(e.ExtraParams.Username, e.ExtraParams.Password))
+			secrets = append(secrets, e.ExtraParams.Password)
+		}
+	}
+	respData, statusCode, err := e.SendRequest(ctx, Elasticsearch, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     strings.TrimSuffix(e.ProviderServerURL, "/") + "/_bulk",
+		Header:  header,
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseElasticsearchError(err)
+	}
+	epr := &elasticsearchBulkResponse{}
+	err = json.Unmarshal(respData, epr)
+	if err != nil {
+		return nil, err
+	}
+	if len(epr.Items) == 0 {
+		return nil, fmt.Errorf("%w: elasticsearch replied no bulk item", ErrHttpRequestFailed)
+	}
+	item := epr.Items[0].Create
+	// conflict means document with the same ID is indexed by previous attempt
+	if item.Error != nil && item.Status != http.StatusConflict {
+		return nil, &ProviderError{
+			Provider: Elasticsearch,
+			Kind:     errorKindByStatus(item.Status),
+			Code:     item.Status,
+			Message:  fmt.Sprintf("%s: %s", item.Error.Type, item.Error.Reason),
+		}
+	}
+	result := item.Result
+	if item.Status == http.StatusConflict {
+		result = "duplicated"
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("Result: %s, Took: %dms", result, epr.Took),
+		Timestamp: time.Now().Unix(),
+		Ack:       item.Index + "/" + item.ID,
+	}, nil
+}
+
+// elasticsearchBulkResponse is replied with 200 even if some items failed, errors is true then
+type elasticsearchBulkResponse struct {
+	Took   int  `json:"took"`
+	Errors bool `json:"errors"`
+	Items  []struct {
+		Create elasticsearchBulkItem `json:"create"`
+	} `json:"items"`
+}
+
+type elasticsearchBulkItem struct {
+	Index  string              `json:"_index"`
+	ID     string              `json:"_id"`
+	Result string              `json:"result"`
+	Status int                 `json:"status"`
+	Error  *elasticsearchError `json:"error,omitempty"`
+}
+
+type elasticsearchError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// parseElasticsearchError turns error response of the whole request into *ProviderError
+func parseElasticsearchError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	eer := &struct {
+		Error elasticsearchError `json:"error"`
+	}{}
+	_ = json.Unmarshal(statusErr.Body, eer)
+	return &ProviderError{
+		Provider: Elasticsearch,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     statusErr.StatusCode,
+		Message:  fmt.Sprintf("%s: %s", eer.Error.Type, eer.Error.Reason),
+		Err:      err,
+	}
+}
+
+type elasticsearchPushProviderExtraParams struct {
+	// Index could be an index, alias or data stream, default is rdpalert
+	Index    string `json:"index,omitempty" validate:"omitempty,lowercase,excludesall=\\/*?\"<> #"`
+	Pipeline string `json:"pipeline,omitempty" validate:"omitempty"`
+	// APIKey is the encoded API key sent as ApiKey token, Username and Password are used for basic auth instead
+	APIKey   string `json:"apiKey,omitempty" validate:"omitempty,excluded_with=Username"`
+	Username string `json:"username,omitempty" validate:"omitempty"`
+	Password string `json:"password,omitempty" validate:"required_with=Username"`
+}
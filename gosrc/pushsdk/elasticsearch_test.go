@@ -0,0 +1,105 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newElasticsearchServer replies bulk response with a single create item, body of request is kept in got
+func newElasticsearchServer(t *testing.T, item string, got *[]byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("request path = %q", r.URL.Path)
+		}
+		if v := r.Header.Get("Content-Type"); v != ndjsonContentType {
+			t.Errorf("Content-Type = %q", v)
+		}
+		*got, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"took":3,"errors":` + boolString(strings.Contains(item, `"error"`)) + `,"items":[{"create":` + item + `}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+func sendElasticsearch(t *testing.T, serverURL string) (*PushResponse, error) {
+	t.Helper()
+	prv := &elasticsearchPushProvider{
+		ProviderCommon:    noRetry(),
+		ProviderServerURL: serverURL,
+		ExtraParams:       &elasticsearchPushProviderExtraParams{Index: "logs-rdp", Username: "elastic", Password: "changeme"},
+	}
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", EventType: EventRDPLoginSuccess})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	return prv.SendPushContent(context.Background(), spc)
+}
+
+func TestElasticsearchBulkCreated(t *testing.T) {
+	var body []byte
+	srv := newElasticsearchServer(t, `{"_index":"logs-rdp","_id":"abc","result":"created","status":201}`, &body)
+	resp, err := sendElasticsearch(t, srv.URL)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "logs-rdp/abc" || !strings.Contains(resp.Message, "created") {
+		t.Errorf("response = %+v", resp)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(body, []byte("\n")), []byte("\n"))
+	if len(lines) != 2 || !bytes.HasSuffix(body, []byte("\n")) {
+		t.Fatalf("body is not an action and a source line: %q", body)
+	}
+	action := map[string]map[string]string{}
+	if err := json.Unmarshal(lines[0], &action); err != nil {
+		t.Fatalf("action line: %v", err)
+	}
+	if action["create"]["_index"] != "logs-rdp" || action["create"]["_id"] == "" {
+		t.Errorf("action = %v, want create with index and id", action)
+	}
+}
+
+func TestElasticsearchBulkConflict(t *testing.T) {
+	// retried document is rejected by create action as it's indexed already
+	var body []byte
+	srv := newElasticsearchServer(t, `{"_index":"logs-rdp","_id":"abc","status":409,`+
+		`"error":{"type":"version_conflict_engine_exception","reason":"[abc]: version conflict, document already exists"}}`, &body)
+	resp, err := sendElasticsearch(t, srv.URL)
+	if err != nil {
+		t.Fatalf("send: %v, want conflict treated as success", err)
+	}
+	if resp.Ack != "logs-rdp/abc" || !strings.Contains(resp.Message, "duplicated") {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestElasticsearchBulkItemError(t *testing.T) {
+	var body []byte
+	srv := newElasticsearchServer(t, `{"_index":"logs-rdp","_id":"abc","status":400,`+
+		`"error":{"type":"mapper_parsing_exception","reason":"failed to parse field [host]"}}`, &body)
+	_, err := sendElasticsearch(t, srv.URL)
+	var pErr *ProviderError
+	if !errors.As(err, &pErr) {
+		t.Fatalf("error = %v, want *ProviderError", err)
+	}
+	if pErr.Code != http.StatusBadRequest || pErr.Kind != ErrHttpRequestFailed || !strings.Contains(pErr.Message, "mapper_parsing_exception") {
+		t.Errorf("error = %+v", pErr)
+	}
+}
@@ -0,0 +1,364 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"rdpalert/utils"
+	"strings"
+	"time"
+)
+
+const (
+	SMTPSecurityImplicitTLS = "implicit"
+	SMTPSecurityStartTLS    = "starttls"
+	SMTPSecurityNone        = "none"
+
+	SMTPAuthPlain = "plain"
+	SMTPAuthLogin = "login"
+)
+
+var (
+	ErrSMTPStartTLSNotSupported = errors.New("smtp server does not support STARTTLS")
+	ErrSMTPUnencryptedAuth      = errors.New("smtp auth over unencrypted connection is refused")
+)
+
+// emailPushContent is a multipart/alternative message, Subject comes from ShortTitle
+type emailPushContent struct {
+	From     string
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+
+	// provider info
+	providerName PushProvider
+}
+
+func (epc *emailPushContent) Init() {
+	epc.SetPushProvider()
+}
+
+func (epc *emailPushContent) Provider() PushProvider {
+	return epc.providerName
+}
+
+func (epc *emailPushContent) SetPushProvider() {
+	epc.providerName = Email
+}
+
+func (epc *emailPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*emailPushProviderExtraParams)
+	epc.From = d1.From
+	epc.To = d1.To
+}
+
+func (epc *emailPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	epc.Subject = g.ShortTitle
+	if epc.Subject == "" {
+		epc.Subject = g.Title
+	}
+	epc.TextBody = g.Title + "\r\n\r\n" + strings.ReplaceAll(g.Description, "\n", "\r\n")
+	buf := &strings.Builder{}
+	buf.WriteString("<!DOCTYPE html><html><body>")
+	buf.WriteString("<h2>" + html.EscapeString(g.Title) + "</h2>")
+	if len(g.Fields) == 0 {
+		buf.WriteString("<pre>" + html.EscapeString(g.Description) + "</pre>")
+	} else {
+		buf.WriteString(`<table cellpadding="4" style="border-collapse:collapse">`)
+		for _, v := range g.Fields {
+			buf.WriteString("<tr><th align=\"left\">" + html.EscapeString(v.Name) + "</th><td>" + html.EscapeString(v.Value) + "</td></tr>")
+		}
+		buf.WriteString("</table>")
+	}
+	buf.WriteString("</body></html>")
+	epc.HTMLBody = buf.String()
+	return epc, nil
+}
+
+// ToBytes renders the whole RFC 5322 message, lines end with CRLF
+func (epc *emailPushContent) ToBytes() ([]byte, error) {
+	msg := &bytes.Buffer{}
+	mw := multipart.NewWriter(msg)
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "localhost"
+	}
+	rb := make([]byte, 12)
+	_, _ = rand.Read(rb)
+	from := epc.From
+	// encode display name if it's not ASCII
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.String()
+	}
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(epc.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("UTF-8", epc.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		fmt.Sprintf("Message-ID: <%s@%s>", hex.EncodeToString(rb), hostname),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	msg.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", epc.TextBody},
+		{"text/html; charset=utf-8", epc.HTMLBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qpw := quotedprintable.NewWriter(pw)
+		_, err = qpw.Write([]byte(part.body))
+		if err != nil {
+			return nil, err
+		}
+		err = qpw.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := mw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+func init() {
+	MustRegisterProvider(Email, func() PushProviderImpl { return &emailPushProvider{} })
+}
+
+type emailPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is smtp://host:port or smtps://host:port, TLS setting is taken from httpClient
+	ProviderServerURL string                        `json:"serverURL" validate:"url,required,startswith=smtp"`
+	ExtraParams       *emailPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (e emailPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(e)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(e.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (e emailPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	epc := &emailPushContent{}
+	epc.Init()
+	epc.AcceptExtParamSettings(e.ExtraParams)
+	return epc.FromGeneral(g)
+}
+
+func (e emailPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*emailPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	policy := e.Retry
+	if policy == nil {
+		policy = mergeRetryPolicy(nil, nil)
+	}
+	err = policy.Do(ctx, string(Email), func(_ int) error {
+		return e.sendMail(ctx, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      250,
+		Message:   fmt.Sprintf("Mail sent to %d recipient(s)", len(e.ExtraParams.To)),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// sendMail runs a single SMTP session, connection is closed once ctx is done,
+// it never asks for a retry once message data is sent, as the mail may be delivered already
+func (e emailPushProvider) sendMail(ctx context.Context, msg []byte) error {
+	serverURL, err := url.Parse(e.ProviderServerURL)
+	if err != nil {
+		return err
+	}
+	host := serverURL.Hostname()
+	port := serverURL.Port()
+	security := e.ExtraParams.Security
+	if security == "" {
+		security = SMTPSecurityStartTLS
+		if serverURL.Scheme == "smtps" {
+			security = SMTPSecurityImplicitTLS
+		}
+	}
+	if port == "" {
+		port = "587"
+		if security == SMTPSecurityImplicitTLS {
+			port = "465"
+		}
+	}
+	tlsConf, err := NewTLSConfig(e.HTTPClient)
+	if err != nil {
+		return err
+	}
+	tlsConf.ServerName = host
+	dialer := &net.Dialer{Timeout: e.HTTPClient.timeoutOrDefault()}
+	var conn net.Conn
+	if security == SMTPSecurityImplicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConf}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	_ = conn.SetDeadline(time.Now().Add(e.HTTPClient.timeoutOrDefault()))
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	localName, _ := os.Hostname()
+	if localName != "" {
+		err = c.Hello(localName)
+		if err != nil {
+			return err
+		}
+	}
+	if security == SMTPSecurityStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return ErrSMTPStartTLSNotSupported
+		}
+		err = c.StartTLS(tlsConf)
+		if err != nil {
+			return err
+		}
+	}
+	if e.ExtraParams.Username != "" {
+		var auth smtp.Auth
+		switch e.ExtraParams.AuthMethod {
+		case SMTPAuthLogin:
+			auth = &smtpLoginAuth{username: e.ExtraParams.Username, password: e.ExtraParams.Password, host: host}
+		default:
+			auth = smtp.PlainAuth("", e.ExtraParams.Username, e.ExtraParams.Password, host)
+		}
+		err = c.Auth(auth)
+		if err != nil {
+			return err
+		}
+	}
+	fromAddr, err := mail.ParseAddress(e.ExtraParams.From)
+	if err != nil {
+		return err
+	}
+	err = c.Mail(fromAddr.Address)
+	if err != nil {
+		return err
+	}
+	for _, v := range e.ExtraParams.To {
+		toAddr, err := mail.ParseAddress(v)
+		if err != nil {
+			return err
+		}
+		err = c.Rcpt(toAddr.Address)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+	// Close ends data and waits for the reply, server discards the message only if it replies an error code,
+	// a lost reply leaves us not knowing whether it's queued
+	err = w.Close()
+	if err != nil {
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) {
+			return err
+		}
+		return markUnretryable(fmt.Errorf("mail may be delivered, reply of data is lost: %w", err))
+	}
+	// message is queued by server now, failure of QUIT doesn't matter
+	err = c.Quit()
+	if err != nil {
+		if gLogger, err2 := utils.GetLoggerInstance(); err2 == nil {
+			gLogger.Warn("Email: mail is accepted but QUIT failed: ", err.Error())
+		}
+	}
+	return nil
+}
+
+// smtpLoginAuth implements LOGIN mechanism, which is not in net/smtp but still required by some relays
+type smtpLoginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *smtpLoginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// same rule as smtp.PlainAuth, never send password in clear text except to localhost
+	if !server.TLS && a.host != "localhost" && a.host != "127.0.0.1" && a.host != "::1" {
+		return "", nil, ErrSMTPUnencryptedAuth
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *smtpLoginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:", "user name", "username":
+		return []byte(a.username), nil
+	case "password:", "password":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge: %s", string(fromServer))
+	}
+}
+
+type emailPushProviderExtraParams struct {
+	// From could be "RDP Alert <rdpalert@corp.local>"
+	From string   `json:"from" validate:"required"`
+	To   []string `json:"to" validate:"required,min=1,dive,email"`
+	// Security is starttls by default, implicit for smtps scheme
+	Security   string `json:"security,omitempty" validate:"omitempty,oneof=implicit starttls none"`
+	AuthMethod string `json:"authMethod,omitempty" validate:"omitempty,oneof=plain login"`
+	Username   string `json:"username,omitempty" validate:"omitempty"`
+	Password   string `json:"password,omitempty" validate:"required_with=Username"`
+}
@@ -0,0 +1,280 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer speaks just enough ESMTP for the provider, STARTTLS is offered before TLS and AUTH after it
+type fakeSMTPServer struct {
+	l       net.Listener
+	tlsConf *tls.Config
+	// dataReply is replied to end of data, connection is dropped without reply if empty
+	dataReply string
+	// dropOnQuit closes connection instead of replying QUIT
+	dropOnQuit bool
+
+	mu       sync.Mutex
+	sessions int
+	startTLS bool
+	auth     string
+	from     string
+	rcpt     []string
+	data     []byte
+}
+
+func newFakeSMTPServer(t *testing.T, cert tls.Certificate) *fakeSMTPServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{l: l, tlsConf: &tls.Config{Certificates: []tls.Certificate{cert}}, dataReply: "250 2.0.0 Ok: queued"}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.sessions++
+			s.mu.Unlock()
+			go s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) url() string {
+	_, port, _ := net.SplitHostPort(s.l.Addr().String())
+	return "smtp://localhost:" + port
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP fake")
+	isTLS := false
+	readBase64 := func(prompt string) string {
+		_ = tp.PrintfLine("334 %s", prompt)
+		line, _ := tp.ReadLine()
+		b, _ := base64.StdEncoding.DecodeString(line)
+		return string(b)
+	}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		s.mu.Lock()
+		switch strings.ToUpper(fields[0]) {
+		case "EHLO":
+			ext := "STARTTLS"
+			if isTLS {
+				ext = "AUTH PLAIN LOGIN"
+			}
+			_ = tp.PrintfLine("250-localhost\r\n250 %s", ext)
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 2.0.0 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConf)
+			if tlsConn.Handshake() != nil {
+				s.mu.Unlock()
+				return
+			}
+			conn, tp, isTLS = tlsConn, textproto.NewConn(tlsConn), true
+			s.startTLS = true
+		case "AUTH":
+			s.mu.Unlock()
+			var auth string
+			switch {
+			case fields[1] == "PLAIN" && len(fields) == 3:
+				b, _ := base64.StdEncoding.DecodeString(fields[2])
+				auth = "PLAIN " + strings.ReplaceAll(string(b), "\x00", "|")
+			case fields[1] == "LOGIN":
+				user := readBase64("VXNlcm5hbWU6")
+				auth = "LOGIN " + user + "|" + readBase64("UGFzc3dvcmQ6")
+			}
+			s.mu.Lock()
+			s.auth = auth
+			_ = tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = strings.TrimPrefix(line, "MAIL FROM:")
+			_ = tp.PrintfLine("250 2.1.0 Ok")
+		case "RCPT":
+			s.rcpt = append(s.rcpt, strings.TrimPrefix(line, "RCPT TO:"))
+			_ = tp.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			s.mu.Unlock()
+			data, err := tp.ReadDotBytes()
+			s.mu.Lock()
+			s.data = data
+			if err != nil || s.dataReply == "" {
+				s.mu.Unlock()
+				return
+			}
+			_ = tp.PrintfLine("%s", s.dataReply)
+		case "QUIT":
+			if !s.dropOnQuit {
+				_ = tp.PrintfLine("221 2.0.0 Bye")
+			}
+			s.mu.Unlock()
+			return
+		default:
+			_ = tp.PrintfLine("250 Ok")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func newTestEmailProvider(serverURL string, caFile string, ext *emailPushProviderExtraParams) *emailPushProvider {
+	ext.From = "RDP Alert <rdpalert@corp.local>"
+	ext.To = []string{"secops@corp.local", "oncall@corp.local"}
+	return &emailPushProvider{
+		ProviderCommon: ProviderCommon{
+			Retry:      &RetryPolicy{MaxAttempts: 3, BaseDelay: ptrTo(Duration(0)), RetryableNetErrors: []string{NetErrAll}},
+			HTTPClient: &HTTPClientConfig{Timeout: Duration(5 * time.Second), CABundleFile: caFile},
+		},
+		ProviderServerURL: serverURL,
+		ExtraParams:       ext,
+	}
+}
+
+func sendTestEmail(t *testing.T, prv *emailPushProvider) error {
+	t.Helper()
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+		Title:       "RDP Login - Success",
+		ShortTitle:  "alice from 10.0.0.8 into 服务器",
+		Description: "From: 10.0.0.8\nUser: CORP\\alice",
+		Fields:      []PushField{{Name: FieldUser, Value: "<alice>"}, {Name: FieldSourceIP, Value: "10.0.0.8"}},
+	})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	_, err = prv.SendPushContent(context.Background(), spc)
+	return err
+}
+
+func TestEmailStartTLSAndAuth(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+	cases := []struct {
+		method   string
+		wantAuth string
+	}{
+		{SMTPAuthPlain, "PLAIN |rdpalert|s3cret"},
+		{SMTPAuthLogin, "LOGIN rdpalert|s3cret"},
+	}
+	for _, c := range cases {
+		t.Run(c.method, func(t *testing.T) {
+			srv := newFakeSMTPServer(t, cert)
+			prv := newTestEmailProvider(srv.url(), caFile, &emailPushProviderExtraParams{
+				AuthMethod: c.method, Username: "rdpalert", Password: "s3cret",
+			})
+			if err := sendTestEmail(t, prv); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if !srv.startTLS {
+				t.Error("STARTTLS is not used")
+			}
+			if srv.auth != c.wantAuth {
+				t.Errorf("auth = %q, want %q", srv.auth, c.wantAuth)
+			}
+			if srv.from != "<rdpalert@corp.local>" || strings.Join(srv.rcpt, ",") != "<secops@corp.local>,<oncall@corp.local>" {
+				t.Errorf("envelope from %s to %v", srv.from, srv.rcpt)
+			}
+			checkTestEmailBody(t, srv.data)
+		})
+	}
+}
+
+func checkTestEmailBody(t *testing.T, data []byte) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "alice from 10.0.0.8 into 服务器" {
+		t.Errorf("subject = %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	wants := []struct {
+		contentType string
+		contains    string
+	}{
+		{"text/plain; charset=utf-8", "From: 10.0.0.8\nUser: CORP\\alice"},
+		{"text/html; charset=utf-8", "<td>&lt;alice&gt;</td>"},
+	}
+	for _, want := range wants {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		if ct := part.Header.Get("Content-Type"); ct != want.contentType {
+			t.Errorf("part content type = %q, want %q", ct, want.contentType)
+		}
+		if !strings.Contains(string(body), want.contains) {
+			t.Errorf("%s part doesn't contain %q:\n%s", want.contentType, want.contains, body)
+		}
+	}
+	if _, err = mr.NextPart(); err != io.EOF {
+		t.Errorf("want exactly 2 parts, got %v", err)
+	}
+}
+
+func TestEmailRetryStopsOnceDataIsSent(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+	cases := []struct {
+		name         string
+		dataReply    string
+		dropOnQuit   bool
+		wantErr      bool
+		wantSessions int
+	}{
+		{"accepted but quit failed", "250 2.0.0 Ok: queued", true, false, 1},
+		{"reply of data lost", "", false, true, 1},
+		{"data rejected temporarily", "451 4.3.0 Try again later", false, true, 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := newFakeSMTPServer(t, cert)
+			srv.dataReply, srv.dropOnQuit = c.dataReply, c.dropOnQuit
+			err := sendTestEmail(t, newTestEmailProvider(srv.url(), caFile, &emailPushProviderExtraParams{}))
+			if (err != nil) != c.wantErr {
+				t.Errorf("err = %v, want error: %v", err, c.wantErr)
+			}
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if srv.sessions != c.wantSessions {
+				t.Errorf("smtp sessions = %d, want %d", srv.sessions, c.wantSessions)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package pushsdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// kinds of ProviderError, ErrRateLimited is also one of them
+var (
+	ErrAuthFailed       = errors.New("authentication failed")
+	ErrInvalidDeviceKey = errors.New("invalid device key or recipient")
+	ErrServerError      = errors.New("push server error")
+)
+
+// ProviderError is a failure reported by push service, Kind is one of ErrAuthFailed, ErrInvalidDeviceKey,
+// ErrRateLimited, ErrServerError or ErrHttpRequestFailed if unknown, check it with errors.Is
+type ProviderError struct {
+	Provider PushProvider
+	Kind     error
+	// Code is status or error code replied by service
+	Code    int
+	Message string
+	// Err is the underlying error, e.g. *HTTPStatusError, could be nil
+	Err error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: code %d, %s", e.Kind.Error(), e.Code, e.Message)
+}
+
+func (e *ProviderError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Temporary reports whether the same push may succeed later, otherwise config needs fixing or another provider should be used
+func (e *ProviderError) Temporary() bool {
+	return errors.Is(e.Kind, ErrRateLimited) || errors.Is(e.Kind, ErrServerError)
+}
+
+// PartialDeliveryError is returned by provider sending to several targets when only some of them are reached,
+// Delivered includes targets reached by previous attempts, Err joins errors of the failed ones
+type PartialDeliveryError struct {
+	Delivered []string
+	Err       error
+}
+
+func (e *PartialDeliveryError) Error() string {
+	return fmt.Sprintf("delivered to %d target(s) only: %s", len(e.Delivered), e.Err.Error())
+}
+
+func (e *PartialDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// parseStatusError turns *HTTPStatusError into *ProviderError by status code only,
+// for services whose error body tells nothing more
+func parseStatusError(provider PushProvider, err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	return &ProviderError{
+		Provider: provider,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     statusErr.StatusCode,
+		Message:  truncateString(strings.TrimSpace(string(statusErr.Body)), 200),
+		Err:      err,
+	}
+}
+
+// errorKindByStatus classifies HTTP status code, providers could refine it with error message
+func errorKindByStatus(code int) error {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrAuthFailed
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= http.StatusInternalServerError:
+		return ErrServerError
+	default:
+		return ErrHttpRequestFailed
+	}
+}
@@ -0,0 +1,95 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseProviderErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		parse     func(error) error
+		provider  PushProvider
+		status    int
+		body      string
+		wantKind  error
+		wantCode  int
+		temporary bool
+	}{
+		{"discord rate limited", parseDiscordError, Discord, 429, `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`, ErrRateLimited, 429, true},
+		{"discord unknown webhook", parseDiscordError, Discord, 404, `{"message":"Unknown Webhook","code":10015}`, ErrInvalidDeviceKey, 10015, false},
+		{"discord invalid token", parseDiscordError, Discord, 401, `{"message":"Invalid Webhook Token","code":50027}`, ErrAuthFailed, 50027, false},
+		{"slack rate limited", parseSlackError, Slack, 429, ``, ErrRateLimited, 429, true},
+		{"slack invalid token", parseSlackError, Slack, 403, `invalid_token`, ErrAuthFailed, 403, false},
+		{"slack archived channel", parseSlackError, Slack, 410, `channel_is_archived`, ErrInvalidDeviceKey, 410, false},
+		{"slack server error", parseSlackError, Slack, 500, `rollup_error`, ErrServerError, 500, true},
+		{"pagerduty rate limited", parsePagerDutyError, PagerDuty, 429, `{"status":"throttle event"}`, ErrRateLimited, 429, true},
+		{"pagerduty invalid event", parsePagerDutyError, PagerDuty, 400, `{"status":"invalid event","message":"Event object is invalid","errors":["Length of 'routing_key' is incorrect (should be 32 characters)"]}`, ErrHttpRequestFailed, 400, false},
+		{"pagerduty bad routing key", parsePagerDutyError, PagerDuty, 400, `{"status":"invalid event","message":"Event object is invalid","errors":["Invalid routing key"]}`, ErrInvalidDeviceKey, 400, false},
+		{"opsgenie rate limited", parseOpsgenieError, Opsgenie, 429, `{"message":"You are making too many requests!","took":0.001,"requestId":"4f5e3b2a"}`, ErrRateLimited, 429, true},
+		{"opsgenie invalid key", parseOpsgenieError, Opsgenie, 401, `{"message":"Key format is not valid!","took":0.001,"requestId":"4f5e3b2a"}`, ErrAuthFailed, 401, false},
+		{"bark unknown device key", parseBarkError, BarkForiOS, 400, `{"code":400,"message":"failed to get device token: failed to get [abc] device token from database","timestamp":1700000000}`, ErrInvalidDeviceKey, 400, false},
+		{"bark apns rejected", parseBarkError, BarkForiOS, 500, `{"code":500,"message":"push failed: BadDeviceToken","timestamp":1700000000}`, ErrInvalidDeviceKey, 500, false},
+		{"bark unauthorized", parseBarkError, BarkForiOS, 401, `{"code":401,"message":"Unauthorized","timestamp":1700000000}`, ErrAuthFailed, 401, false},
+		{"bark proxy error page", parseBarkError, BarkForiOS, 502, `<html>Bad Gateway</html>`, ErrServerError, 502, true},
+		{"telegram chat not found", parseTelegramError, Telegram, 400, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`, ErrInvalidDeviceKey, 400, false},
+		{"telegram bot blocked", parseTelegramError, Telegram, 403, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`, ErrInvalidDeviceKey, 403, false},
+		{"telegram wrong token", parseTelegramError, Telegram, 401, `{"ok":false,"error_code":401,"description":"Unauthorized"}`, ErrAuthFailed, 401, false},
+		{"telegram too many requests", parseTelegramError, Telegram, 429, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`, ErrRateLimited, 429, true},
+		{"gotify wrong token", parseGotifyError, Gotify, 401, `{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token or user credentials to access this api"}`, ErrAuthFailed, 401, false},
+		{"gotify forbidden", parseGotifyError, Gotify, 403, `{"error":"Forbidden","errorCode":403,"errorDescription":"you are not allowed to access this api"}`, ErrAuthFailed, 403, false},
+		{"ntfy forbidden", parseNtfyError, Ntfy, 403, `{"code":40301,"http":403,"error":"forbidden","link":"https://ntfy.sh/docs/publish/#authentication"}`, ErrAuthFailed, 40301, false},
+		{"ntfy rate limited", parseNtfyError, Ntfy, 429, `{"code":42901,"http":429,"error":"limit reached: too many requests"}`, ErrRateLimited, 42901, true},
+		{"dingtalk server error", func(err error) error { return parseStatusError(DingTalk, err) }, DingTalk, 502, `Bad Gateway`, ErrServerError, 502, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			statusErr := &HTTPStatusError{StatusCode: c.status, Body: []byte(c.body), RetryAfter: 2 * time.Second}
+			err := c.parse(statusErr)
+			var provErr *ProviderError
+			if !errors.As(err, &provErr) {
+				t.Fatalf("want *ProviderError, got %T: %v", err, err)
+			}
+			if provErr.Provider != c.provider || provErr.Code != c.wantCode {
+				t.Errorf("provider %s code %d, want %s code %d", provErr.Provider, provErr.Code, c.provider, c.wantCode)
+			}
+			if !errors.Is(err, c.wantKind) {
+				t.Errorf("kind = %v, want %v", provErr.Kind, c.wantKind)
+			}
+			if provErr.Temporary() != c.temporary {
+				t.Errorf("temporary = %v, want %v", provErr.Temporary(), c.temporary)
+			}
+			if !errors.Is(err, statusErr) {
+				t.Errorf("status error is not kept in chain")
+			}
+		})
+	}
+}
+
+func TestBarkErrorInOKReply(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":400,"message":"failed to get device token","timestamp":1700000000}`))
+	}))
+	defer srv.Close()
+	prv := barkPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/push",
+		ExtraParams: &barkPushProviderExtraParams{DeviceKeys: []string{"key1"}}}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := prv.SendPushContent(context.Background(), spc)
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) {
+		t.Fatalf("want *ProviderError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, ErrInvalidDeviceKey) || provErr.Code != 400 || provErr.Temporary() {
+		t.Errorf("error = %v, code %d", err, provErr.Code)
+	}
+	if resp == nil || resp.Code != 400 {
+		t.Errorf("push response = %v, want the replied one", resp)
+	}
+}
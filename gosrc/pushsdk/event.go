@@ -0,0 +1,41 @@
+package pushsdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// alertEvent is the structured record of an alert written into event sinks, e.g. Splunk, Elasticsearch and Loki
+type alertEvent struct {
+	Timestamp time.Time `json:"@timestamp"`
+	EventType string    `json:"event_type,omitempty"`
+	Severity  Severity  `json:"severity"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Domain    string    `json:"domain,omitempty"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	Host      string    `json:"host,omitempty"`
+	HostIPs   string    `json:"host_ips,omitempty"`
+	// ID is stable for the same alert, sinks use it to drop duplicates from retries
+	ID string `json:"event_id"`
+}
+
+func newAlertEvent(g *GeneralPushContent) *alertEvent {
+	e := &alertEvent{
+		Timestamp: g.OccurredAtOrNow().UTC(),
+		EventType: g.EventType,
+		Severity:  g.SeverityOrDefault(),
+		Title:     g.Title,
+		Summary:   g.ShortTitle,
+		User:      g.Field(FieldUser),
+		Domain:    g.Field(FieldDomain),
+		SourceIP:  g.Field(FieldSourceIP),
+		Host:      g.Field(FieldHost),
+		HostIPs:   g.Field(FieldHostIPs),
+	}
+	h := sha256.Sum256([]byte(g.DedupKey() + "\x00" + e.Timestamp.Format(time.RFC3339Nano)))
+	e.ID = hex.EncodeToString(h[:16])
+	return e
+}
@@ -0,0 +1,281 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FeishuFormatPost        = "post"
+	FeishuFormatInteractive = "interactive"
+
+	// feishuCodeTooManyRequests is returned when bot exceeds 100 messages per minute
+	feishuCodeTooManyRequests = 9499
+	// feishuCodeSignMismatch is returned when sign is wrong or timestamp is more than one hour away
+	feishuCodeSignMismatch = 19021
+	// feishuCodeIPNotAllowed is returned when caller IP is not in whitelist
+	feishuCodeIPNotAllowed = 19022
+)
+
+// feishuCodeKinds classifies code of bot response
+var feishuCodeKinds = map[int]error{
+	feishuCodeTooManyRequests: ErrRateLimited,
+	feishuCodeSignMismatch:    ErrAuthFailed,
+	feishuCodeIPNotAllowed:    ErrAuthFailed,
+}
+
+// feishuCardTemplates maps Severity to card header color
+var feishuCardTemplates = map[Severity]string{
+	SeverityInfo:     "blue",
+	SeverityWarning:  "orange",
+	SeverityError:    "red",
+	SeverityCritical: "carmine",
+}
+
+// feishuPushContent is a custom bot message, check: https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot
+// Timestamp and Sign are only set when signature verification is enabled
+type feishuPushContent struct {
+	Timestamp string         `json:"timestamp,omitempty"`
+	Sign      string         `json:"sign,omitempty"`
+	MsgType   string         `json:"msg_type" validate:"required"`
+	Content   *feishuContent `json:"content,omitempty"`
+	Card      *feishuCard    `json:"card,omitempty"`
+
+	actionTitle string
+	actionURL   string
+	// provider info
+	providerName PushProvider
+}
+
+type feishuContent struct {
+	Post map[string]feishuPost `json:"post"`
+}
+
+// feishuPost is rich text, every paragraph is a list of text or link elements
+type feishuPost struct {
+	Title   string                `json:"title"`
+	Content [][]feishuPostElement `json:"content"`
+}
+
+type feishuPostElement struct {
+	Tag  string `json:"tag"`
+	Text string `json:"text"`
+	Href string `json:"href,omitempty"`
+}
+
+type feishuCard struct {
+	Config   map[string]bool     `json:"config"`
+	Header   feishuCardHeader    `json:"header"`
+	Elements []feishuCardElement `json:"elements"`
+}
+
+type feishuCardHeader struct {
+	Title    feishuCardText `json:"title"`
+	Template string         `json:"template"`
+}
+
+type feishuCardText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+// feishuCardElement covers div and action elements
+type feishuCardElement struct {
+	Tag     string             `json:"tag"`
+	Text    *feishuCardText    `json:"text,omitempty"`
+	Fields  []feishuCardField  `json:"fields,omitempty"`
+	Actions []feishuCardButton `json:"actions,omitempty"`
+}
+
+type feishuCardField struct {
+	IsShort bool           `json:"is_short"`
+	Text    feishuCardText `json:"text"`
+}
+
+type feishuCardButton struct {
+	Tag  string         `json:"tag"`
+	Text feishuCardText `json:"text"`
+	Type string         `json:"type"`
+	URL  string         `json:"url"`
+}
+
+func (fpc *feishuPushContent) Init() {
+	fpc.MsgType = FeishuFormatPost
+	fpc.SetPushProvider()
+}
+
+func (fpc *feishuPushContent) Provider() PushProvider {
+	return fpc.providerName
+}
+
+func (fpc *feishuPushContent) SetPushProvider() {
+	fpc.providerName = Feishu
+}
+
+func (fpc *feishuPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*feishuPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Format != "" {
+		fpc.MsgType = d1.Format
+	}
+	fpc.actionTitle = d1.ActionTitle
+	fpc.actionURL = d1.ActionURL
+}
+
+// FromGeneral uses plain text elements only, so login info is never interpreted as lark_md
+func (fpc *feishuPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	lines := make([]string, 0, len(g.Fields))
+	if len(g.Fields) == 0 {
+		lines = strings.Split(strings.TrimSpace(g.Description), "\n")
+	}
+	for _, v := range g.Fields {
+		lines = append(lines, v.Name+": "+v.Value)
+	}
+	switch fpc.MsgType {
+	case FeishuFormatInteractive:
+		div := feishuCardElement{Tag: "div"}
+		for _, v := range lines {
+			div.Fields = append(div.Fields, feishuCardField{Text: feishuCardText{Tag: "plain_text", Content: v}})
+		}
+		elements := []feishuCardElement{div}
+		if fpc.actionURL != "" {
+			elements = append(elements, feishuCardElement{
+				Tag: "action",
+				Actions: []feishuCardButton{{
+					Tag:  "button",
+					Text: feishuCardText{Tag: "plain_text", Content: fpc.actionTitle},
+					Type: "primary",
+					URL:  fpc.actionURL,
+				}},
+			})
+		}
+		fpc.Card = &feishuCard{
+			Config: map[string]bool{"wide_screen_mode": true},
+			Header: feishuCardHeader{
+				Title:    feishuCardText{Tag: "plain_text", Content: g.Title},
+				Template: feishuCardTemplates[g.SeverityOrDefault()],
+			},
+			Elements: elements,
+		}
+	default:
+		post := feishuPost{Title: g.Title}
+		for _, v := range lines {
+			post.Content = append(post.Content, []feishuPostElement{{Tag: "text", Text: v}})
+		}
+		if fpc.actionURL != "" {
+			post.Content = append(post.Content, []feishuPostElement{{Tag: "a", Text: fpc.actionTitle, Href: fpc.actionURL}})
+		}
+		fpc.Content = &feishuContent{Post: map[string]feishuPost{"zh_cn": post}}
+	}
+	return fpc, nil
+}
+
+func (fpc *feishuPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(fpc)
+}
+
+func init() {
+	MustRegisterProvider(Feishu, func() PushProviderImpl { return &feishuPushProvider{} })
+}
+
+type feishuPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is bot webhook URL, https://open.feishu.cn/open-apis/bot/v2/hook/xxx,
+	// or https://open.larksuite.com/open-apis/bot/v2/hook/xxx for Lark
+	ProviderServerURL string                         `json:"serverURL" validate:"url,required"`
+	ExtraParams       *feishuPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (f feishuPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(f)
+	if err1 != nil {
+		return err1
+	}
+	if f.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(f.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (f feishuPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	fpc := &feishuPushContent{}
+	fpc.Init()
+	fpc.AcceptExtParamSettings(f.ExtraParams)
+	return fpc.FromGeneral(g)
+}
+
+func (f feishuPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*feishuPushContent)
+	if f.ExtraParams != nil && f.ExtraParams.Secret != "" {
+		pData.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		pData.Sign = signFeishu(pData.Timestamp, f.ExtraParams.Secret)
+	}
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if u, err := url.Parse(f.ProviderServerURL); err == nil {
+		secrets = append(secrets, u.Path)
+	}
+	respData, _, err := f.SendRequest(ctx, Feishu, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     f.ProviderServerURL,
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseStatusError(Feishu, err)
+	}
+	fpr := &feishuPushResponse{}
+	err = json.Unmarshal(respData, fpr)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PushResponse{
+		Code:      fpr.Code,
+		Message:   fpr.Msg,
+		Timestamp: time.Now().Unix(),
+	}
+	if fpr.Code != 0 {
+		return resp, newCodeError(Feishu, fpr.Code, fpr.Msg, feishuCodeKinds)
+	}
+	return resp, nil
+}
+
+// signFeishu signs timestamp in seconds, the string to sign is the HMAC key and message is empty,
+// check: https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot#3c6592d6
+func signFeishu(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// feishuPushResponse uses code instead of errcode, HTTP status is 200 even on error
+type feishuPushResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+type feishuPushProviderExtraParams struct {
+	// Secret enables signature verification
+	Secret string `json:"secret,omitempty" validate:"omitempty"`
+	// Format is post (rich text) by default
+	Format      string `json:"format,omitempty" validate:"omitempty,oneof=post interactive"`
+	ActionURL   string `json:"actionURL,omitempty" validate:"omitempty,url"`
+	ActionTitle string `json:"actionTitle,omitempty" validate:"required_with=ActionURL"`
+}
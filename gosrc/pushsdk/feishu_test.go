@@ -0,0 +1,76 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignFeishu(t *testing.T) {
+	// unlike DingTalk, timestamp in seconds and secret joined by a newline is the HMAC key, message is empty
+	got := signFeishu("1700000000", "qD8mB3lKpZ")
+	if want := "BYoF+p1EoxYS4jE2cTlVvXafRJsWgqzR8ImVyQgqjYc="; got != want {
+		t.Errorf("sign = %s, want %s", got, want)
+	}
+	if got == signDingTalk("1700000000", "qD8mB3lKpZ") {
+		t.Errorf("feishu sign must not follow DingTalk layout")
+	}
+}
+
+func TestFeishuCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		wantKind error
+		wantCode int
+	}{
+		{"success", `{"code":0,"msg":"success","data":{}}`, nil, 0},
+		{"too many requests", `{"code":9499,"msg":"too many request","data":{}}`, ErrRateLimited, 9499},
+		{"sign mismatch", `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`, ErrAuthFailed, 19021},
+		{"ip not allowed", `{"code":19022,"msg":"Ip Not Allowed"}`, ErrAuthFailed, 19022},
+		{"keywords not found", `{"code":19024,"msg":"Key Words Not Found"}`, ErrHttpRequestFailed, 19024},
+	}
+	g := &GeneralPushContent{Title: "RDP Login - Success", Description: "alice"}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := &feishuPushContent{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(got)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer srv.Close()
+			prv := feishuPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/open-apis/bot/v2/hook/abc",
+				ExtraParams: &feishuPushProviderExtraParams{Secret: "qD8mB3lKpZ"}}
+			spc, err := prv.TransformToSpecificPushContent(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := prv.SendPushContent(context.Background(), spc)
+			if got.Timestamp == "" || got.Sign != signFeishu(got.Timestamp, "qD8mB3lKpZ") {
+				t.Errorf("timestamp %q sign %q", got.Timestamp, got.Sign)
+			}
+			if c.wantKind == nil {
+				if err != nil {
+					t.Fatalf("send: %v", err)
+				}
+				return
+			}
+			var provErr *ProviderError
+			if !errors.As(err, &provErr) {
+				t.Fatalf("want *ProviderError, got %T: %v", err, err)
+			}
+			if provErr.Provider != Feishu || provErr.Code != c.wantCode || !errors.Is(err, c.wantKind) {
+				t.Errorf("got %s code %d %v, want code %d %v", provErr.Provider, provErr.Code, provErr.Kind, c.wantCode, c.wantKind)
+			}
+			if provErr.Temporary() != (c.wantKind == ErrRateLimited) {
+				t.Errorf("temporary = %v", provErr.Temporary())
+			}
+			if resp == nil || resp.Code != c.wantCode {
+				t.Errorf("push response = %v, want the replied one", resp)
+			}
+		})
+	}
+}
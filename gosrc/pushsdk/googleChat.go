@@ -0,0 +1,209 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// gchatPushContent is a message with single cardV2, check: https://developers.google.com/workspace/chat/api/reference/rest/v1/cards
+type gchatPushContent struct {
+	Text    string        `json:"text,omitempty"`
+	CardsV2 []gchatCardV2 `json:"cardsV2" validate:"required,min=1"`
+
+	actionTitle string
+	actionURL   string
+	// provider info
+	providerName PushProvider
+}
+
+type gchatCardV2 struct {
+	CardID string    `json:"cardId"`
+	Card   gchatCard `json:"card"`
+}
+
+type gchatCard struct {
+	Header   gchatCardHeader    `json:"header"`
+	Sections []gchatCardSection `json:"sections"`
+}
+
+type gchatCardHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type gchatCardSection struct {
+	Header  string            `json:"header,omitempty"`
+	Widgets []gchatCardWidget `json:"widgets"`
+}
+
+// gchatCardWidget covers decoratedText, textParagraph and buttonList widgets
+type gchatCardWidget struct {
+	DecoratedText *gchatDecoratedText `json:"decoratedText,omitempty"`
+	TextParagraph *gchatTextParagraph `json:"textParagraph,omitempty"`
+	ButtonList    *gchatButtonList    `json:"buttonList,omitempty"`
+}
+
+type gchatDecoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+}
+
+type gchatTextParagraph struct {
+	Text string `json:"text"`
+}
+
+type gchatButtonList struct {
+	Buttons []gchatButton `json:"buttons"`
+}
+
+type gchatButton struct {
+	Text    string `json:"text"`
+	OnClick struct {
+		OpenLink struct {
+			URL string `json:"url"`
+		} `json:"openLink"`
+	} `json:"onClick"`
+}
+
+func (gpc *gchatPushContent) Init() {
+	gpc.SetPushProvider()
+}
+
+func (gpc *gchatPushContent) Provider() PushProvider {
+	return gpc.providerName
+}
+
+func (gpc *gchatPushContent) SetPushProvider() {
+	gpc.providerName = GoogleChat
+}
+
+func (gpc *gchatPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*cardPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	gpc.actionTitle = d1.ActionTitle
+	gpc.actionURL = d1.ActionURL
+}
+
+// FromGeneral renders login fields as decoratedText widgets, text widgets accept HTML so everything is escaped
+func (gpc *gchatPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	section := gchatCardSection{Header: html.EscapeString(g.ShortTitle)}
+	if len(g.Fields) == 0 {
+		section.Widgets = append(section.Widgets, gchatCardWidget{
+			TextParagraph: &gchatTextParagraph{Text: html.EscapeString(g.Description)},
+		})
+	} else {
+		for _, v := range g.Fields {
+			section.Widgets = append(section.Widgets, gchatCardWidget{
+				DecoratedText: &gchatDecoratedText{TopLabel: v.Name, Text: html.EscapeString(v.Value)},
+			})
+		}
+	}
+	if gpc.actionURL != "" {
+		btn := gchatButton{Text: gpc.actionTitle}
+		btn.OnClick.OpenLink.URL = gpc.actionURL
+		section.Widgets = append(section.Widgets, gchatCardWidget{ButtonList: &gchatButtonList{Buttons: []gchatButton{btn}}})
+	}
+	gpc.Text = g.Title
+	gpc.CardsV2 = []gchatCardV2{{
+		CardID: "rdpalert",
+		Card: gchatCard{
+			Header:   gchatCardHeader{Title: g.Title, Subtitle: g.Field(FieldHost)},
+			Sections: []gchatCardSection{section},
+		},
+	}}
+	return gpc, nil
+}
+
+func (gpc *gchatPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(gpc)
+}
+
+func init() {
+	MustRegisterProvider(GoogleChat, func() PushProviderImpl { return &gchatPushProvider{} })
+}
+
+type gchatPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is space webhook URL, https://chat.googleapis.com/v1/spaces/xxx/messages?key=xxx&token=xxx
+	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
+	ExtraParams       *cardPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (g gchatPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(g)
+	if err1 != nil {
+		return err1
+	}
+	if g.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(g.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (g gchatPushProvider) TransformToSpecificPushContent(gc *GeneralPushContent) (PushContent, error) {
+	gpc := &gchatPushContent{}
+	gpc.Init()
+	gpc.AcceptExtParamSettings(g.ExtraParams)
+	return gpc.FromGeneral(gc)
+}
+
+func (g gchatPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*gchatPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	reqURL, err := url.Parse(g.ProviderServerURL)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if reqURL.RawQuery != "" {
+		secrets = append(secrets, reqURL.RawQuery)
+	}
+	if g.ExtraParams != nil && g.ExtraParams.ThreadKey != "" {
+		query := reqURL.Query()
+		query.Set("threadKey", g.ExtraParams.ThreadKey)
+		query.Set("messageReplyOption", "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+		reqURL.RawQuery = query.Encode()
+		secrets = append(secrets, reqURL.RawQuery)
+	}
+	respData, statusCode, err := g.SendRequest(ctx, GoogleChat, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     reqURL.String(),
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	gpr := &gchatPushResponse{}
+	err = json.Unmarshal(respData, gpr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("Message: %s, Thread: %s", gpr.Name, gpr.Thread.Name),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+type gchatPushResponse struct {
+	Name   string `json:"name"`
+	Thread struct {
+		Name string `json:"name"`
+	} `json:"thread"`
+}
@@ -0,0 +1,79 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGoogleChatCard(t *testing.T) {
+	var (
+		got   map[string]any
+		query map[string][]string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"name":"spaces/AAAA/messages/BBBB","thread":{"name":"spaces/AAAA/threads/CCCC"}}`))
+	}))
+	defer srv.Close()
+	g := &GeneralPushContent{
+		Title:      "RDP Login - Success",
+		ShortTitle: "<b>alice</b> from 10.0.0.8",
+		Fields:     []PushField{{Name: FieldUser, Value: `<a href="https://evil.example">admin</a>`}, {Name: FieldHost, Value: "HOST-01"}},
+	}
+	prv := gchatPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/v1/spaces/AAAA/messages?key=k&token=t",
+		ExtraParams: &cardPushProviderExtraParams{ActionURL: "https://jump.corp.local", ActionTitle: "Open portal", ThreadKey: "rdp-HOST-01"}}
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := prv.SendPushContent(context.Background(), spc)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Message != "Message: spaces/AAAA/messages/BBBB, Thread: spaces/AAAA/threads/CCCC" {
+		t.Errorf("push response %s", resp.Message)
+	}
+
+	wantQuery := map[string][]string{"key": {"k"}, "token": {"t"}, "threadKey": {"rdp-HOST-01"}, "messageReplyOption": {"REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD"}}
+	if !reflect.DeepEqual(query, wantQuery) {
+		t.Errorf("query = %v, want %v", query, wantQuery)
+	}
+	var want map[string]any
+	_ = json.Unmarshal([]byte(`{
+		"text": "RDP Login - Success",
+		"cardsV2": [{"cardId": "rdpalert", "card": {
+			"header": {"title": "RDP Login - Success", "subtitle": "HOST-01"},
+			"sections": [{"header": "&lt;b&gt;alice&lt;/b&gt; from 10.0.0.8", "widgets": [
+				{"decoratedText": {"topLabel": "User", "text": "&lt;a href=&#34;https://evil.example&#34;&gt;admin&lt;/a&gt;"}},
+				{"decoratedText": {"topLabel": "Host", "text": "HOST-01"}},
+				{"buttonList": {"buttons": [{"text": "Open portal", "onClick": {"openLink": {"url": "https://jump.corp.local"}}}]}}
+			]}]
+		}}]
+	}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("message = %v\nwant %v", got, want)
+	}
+
+	// without thread key, webhook URL is kept as is
+	prv.ExtraParams = nil
+	spc, _ = prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice & <bob>"})
+	if _, err = prv.SendPushContent(context.Background(), spc); err != nil {
+		t.Fatalf("send without thread: %v", err)
+	}
+	if !reflect.DeepEqual(query, map[string][]string{"key": {"k"}, "token": {"t"}}) {
+		t.Errorf("query without thread = %v", query)
+	}
+	widgets := got["cardsV2"].([]any)[0].(map[string]any)["card"].(map[string]any)["sections"].([]any)[0].(map[string]any)["widgets"].([]any)
+	if text := widgets[0].(map[string]any)["textParagraph"].(map[string]any)["text"]; text != "alice &amp; &lt;bob&gt;" || len(widgets) != 1 {
+		t.Errorf("widgets = %v", widgets)
+	}
+}
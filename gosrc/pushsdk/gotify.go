@@ -0,0 +1,195 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultGotifyPriority maps Severity to gotify priority, clients show sound and banner from 4 and above
+var defaultGotifyPriority = map[Severity]int{
+	SeverityInfo:     5,
+	SeverityWarning:  7,
+	SeverityError:    8,
+	SeverityCritical: 10,
+}
+
+// markdownEscaper makes login info literal text in markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`,
+	"(", `\(`, ")", `\)`, "#", `\#`, "+", `\+`, "-", `\-`, "!", `\!`, "|", `\|`, "<", `\<`, ">", `\>`,
+)
+
+// gotifyPushContent is an instance of POST /message, message is always rendered as markdown
+type gotifyPushContent struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message" validate:"required"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+
+	priorityMap map[Severity]int
+	// provider info
+	providerName PushProvider
+}
+
+func (gpc *gotifyPushContent) Init() {
+	gpc.priorityMap = defaultGotifyPriority
+	gpc.Extras = map[string]any{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	gpc.SetPushProvider()
+}
+
+func (gpc *gotifyPushContent) Provider() PushProvider {
+	return gpc.providerName
+}
+
+func (gpc *gotifyPushContent) SetPushProvider() {
+	gpc.providerName = Gotify
+}
+
+func (gpc *gotifyPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*gotifyPushProviderExtraParams)
+	if len(d1.PriorityMap) != 0 {
+		gpc.priorityMap = make(map[Severity]int, len(defaultGotifyPriority))
+		for k, v := range defaultGotifyPriority {
+			gpc.priorityMap[k] = v
+		}
+		for k, v := range d1.PriorityMap {
+			gpc.priorityMap[k] = v
+		}
+	}
+	if d1.ClickURL != "" {
+		gpc.Extras["client::notification"] = map[string]any{
+			"click": map[string]string{"url": d1.ClickURL},
+		}
+	}
+}
+
+// FromGeneral renders fields as markdown list, lines end with two spaces to keep line breaks
+func (gpc *gotifyPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	gpc.Title = g.Title
+	gpc.Priority = gpc.priorityMap[g.SeverityOrDefault()]
+	lines := make([]string, 0, len(g.Fields))
+	if len(g.Fields) == 0 {
+		for _, v := range strings.Split(strings.TrimSpace(g.Description), "\n") {
+			lines = append(lines, markdownEscaper.Replace(strings.TrimSpace(v)))
+		}
+		gpc.Message = strings.Join(lines, "  \n")
+		return gpc, nil
+	}
+	for _, v := range g.Fields {
+		lines = append(lines, fmt.Sprintf("**%s**: %s", markdownEscaper.Replace(v.Name), markdownEscaper.Replace(v.Value)))
+	}
+	gpc.Message = markdownEscaper.Replace(g.ShortTitle) + "\n\n" + strings.Join(lines, "  \n")
+	return gpc, nil
+}
+
+func (gpc *gotifyPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(gpc)
+}
+
+func init() {
+	MustRegisterProvider(Gotify, func() PushProviderImpl { return &gotifyPushProvider{} })
+}
+
+type gotifyPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is base URL of gotify server, e.g. https://gotify.corp.local
+	ProviderServerURL string                         `json:"serverURL" validate:"url,required"`
+	ExtraParams       *gotifyPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (g gotifyPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(g)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(g.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (g gotifyPushProvider) TransformToSpecificPushContent(gc *GeneralPushContent) (PushContent, error) {
+	gpc := &gotifyPushContent{}
+	gpc.Init()
+	gpc.AcceptExtParamSettings(g.ExtraParams)
+	return gpc.FromGeneral(gc)
+}
+
+func (g gotifyPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*gotifyPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	respData, statusCode, err := g.SendRequest(ctx, Gotify, &HTTPRequest{
+		Method: http.MethodPost,
+		URL:    strings.TrimSuffix(g.ProviderServerURL, "/") + "/message",
+		Header: http.Header{
+			"Content-Type": {postJSONContentType},
+			"X-Gotify-Key": {g.ExtraParams.AppToken},
+		},
+		Body: body,
+	})
+	if err != nil {
+		return nil, parseGotifyError(err)
+	}
+	gpr := &gotifyPushResponse{}
+	err = json.Unmarshal(respData, gpr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("MessageID: %d, AppID: %d", gpr.ID, gpr.AppID),
+		Timestamp: gpr.Date.Unix(),
+	}, nil
+}
+
+type gotifyPushResponse struct {
+	ID    int64     `json:"id"`
+	AppID int64     `json:"appid"`
+	Date  time.Time `json:"date"`
+}
+
+// gotifyErrorResponse is replied with status other than 200, check: https://gotify.net/api-docs
+type gotifyErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorCode        int    `json:"errorCode"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+// parseGotifyError turns error response into *ProviderError, 401 and 403 mean app token is wrong
+func parseGotifyError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	ger := &gotifyErrorResponse{}
+	msg := truncateString(strings.TrimSpace(string(statusErr.Body)), 200)
+	if json.Unmarshal(statusErr.Body, ger) == nil && ger.Error != "" {
+		msg = fmt.Sprintf("%s: %s", ger.Error, ger.ErrorDescription)
+	}
+	return &ProviderError{
+		Provider: Gotify,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     statusErr.StatusCode,
+		Message:  msg,
+		Err:      err,
+	}
+}
+
+type gotifyPushProviderExtraParams struct {
+	AppToken string `json:"appToken" validate:"required"`
+	// PriorityMap overrides defaultGotifyPriority
+	PriorityMap map[Severity]int `json:"priorityMap,omitempty" validate:"omitempty,dive,keys,oneof=info warning error critical,endkeys,gte=0,lte=10"`
+	// ClickURL is opened when notification is tapped
+	ClickURL string `json:"clickURL,omitempty" validate:"omitempty,url"`
+}
@@ -0,0 +1,128 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fakeGotify accepts messages with token as X-Gotify-Key only
+type fakeGotify struct {
+	token string
+
+	path string
+	got  map[string]any
+}
+
+func (f *fakeGotify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.path = r.URL.Path
+	f.got = nil
+	_ = json.NewDecoder(r.Body).Decode(&f.got)
+	switch r.Header.Get("X-Gotify-Key") {
+	case f.token:
+		_, _ = w.Write([]byte(`{"id":25,"appid":5,"message":"","title":"","priority":7,"date":"2024-05-01T08:00:00Z"}`))
+	case "":
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token or user credentials to access this api"}`))
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"Forbidden","errorCode":403,"errorDescription":"you are not allowed to access this api"}`))
+	}
+}
+
+func sendGotify(t *testing.T, url string, extParams *gotifyPushProviderExtraParams, g *GeneralPushContent) (*PushResponse, error) {
+	t.Helper()
+	prv := gotifyPushProvider{ProviderCommon: noRetry(), ProviderServerURL: url, ExtraParams: extParams}
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(g)
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	return prv.SendPushContent(context.Background(), spc)
+}
+
+func TestGotifyMessage(t *testing.T) {
+	f := &fakeGotify{token: "AzSXaRTzn3Fy1bo"}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	g := &GeneralPushContent{
+		Title:      "RDP Login - Success",
+		ShortTitle: "alice from 10.0.0.8",
+		Severity:   SeverityWarning,
+		Fields:     []PushField{{Name: FieldUser, Value: "CORP\\*alice*"}, {Name: FieldSourceIP, Value: "10.0.0.8"}},
+	}
+	resp, err := sendGotify(t, srv.URL+"/", &gotifyPushProviderExtraParams{AppToken: "AzSXaRTzn3Fy1bo", ClickURL: "https://siem.corp.local/rdp"}, g)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if f.path != "/message" || resp.Message != "MessageID: 25, AppID: 5" {
+		t.Errorf("path %s, push response %s", f.path, resp.Message)
+	}
+	want := map[string]any{
+		"title":    "RDP Login - Success",
+		"message":  "alice from 10.0.0.8\n\n**User**: CORP\\\\\\*alice\\*  \n**Source IP**: 10.0.0.8",
+		"priority": 7.0,
+		"extras": map[string]any{
+			"client::display":      map[string]any{"contentType": "text/markdown"},
+			"client::notification": map[string]any{"click": map[string]any{"url": "https://siem.corp.local/rdp"}},
+		},
+	}
+	if !reflect.DeepEqual(f.got, want) {
+		t.Errorf("message = %v\nwant %v", f.got, want)
+	}
+}
+
+func TestGotifyPriority(t *testing.T) {
+	f := &fakeGotify{token: "AzSXaRTzn3Fy1bo"}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	cases := []struct {
+		priorityMap map[Severity]int
+		severity    Severity
+		want        float64
+	}{
+		{nil, "", 5},
+		{nil, SeverityInfo, 5},
+		{nil, SeverityWarning, 7},
+		{nil, SeverityError, 8},
+		{nil, SeverityCritical, 10},
+		{map[Severity]int{SeverityWarning: 4}, SeverityWarning, 4},
+		{map[Severity]int{SeverityWarning: 4}, SeverityCritical, 10},
+	}
+	for _, c := range cases {
+		_, err := sendGotify(t, srv.URL, &gotifyPushProviderExtraParams{AppToken: "AzSXaRTzn3Fy1bo", PriorityMap: c.priorityMap},
+			&GeneralPushContent{Title: "RDP Login - Success", Description: "alice", Severity: c.severity})
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if f.got["priority"] != c.want {
+			t.Errorf("severity %q with %v: priority = %v, want %v", c.severity, c.priorityMap, f.got["priority"], c.want)
+		}
+		if _, ok := f.got["extras"].(map[string]any)["client::notification"]; ok {
+			t.Errorf("click extra is sent without clickURL")
+		}
+	}
+}
+
+func TestGotifyAuthFailed(t *testing.T) {
+	srv := httptest.NewServer(&fakeGotify{token: "AzSXaRTzn3Fy1bo"})
+	defer srv.Close()
+	for _, c := range []struct {
+		token string
+		code  int
+	}{{"", http.StatusUnauthorized}, {"wrong", http.StatusForbidden}} {
+		prv := gotifyPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL, ExtraParams: &gotifyPushProviderExtraParams{AppToken: c.token}}
+		spc, _ := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice"})
+		_, err := prv.SendPushContent(context.Background(), spc)
+		var provErr *ProviderError
+		if !errors.As(err, &provErr) || !errors.Is(err, ErrAuthFailed) || provErr.Code != c.code || provErr.Temporary() {
+			t.Errorf("token %q: want ErrAuthFailed with code %d, got %v", c.token, c.code, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package pushsdk
+
+import (
+	"html"
+	"unicode/utf8"
+)
+
+// truncateString keeps at most n characters of s including the trailing ellipsis,
+// so the result fits length limit of the field it goes into
+func truncateString(s string, n int) string {
+	const ellipsis = "..."
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if n <= len(ellipsis) {
+		return string(runes[:max(n, 0)])
+	}
+	return string(runes[:n-len(ellipsis)]) + ellipsis
+}
+
+// escapeHTMLWithin truncates s before escaping it, so that no entity is cut in half
+// and the escaped result including the trailing ellipsis keeps within n characters
+func escapeHTMLWithin(s string, n int) string {
+	escaped := html.EscapeString(s)
+	if utf8.RuneCountInString(escaped) <= n {
+		return escaped
+	}
+	const ellipsis = "..."
+	if n <= len(ellipsis) {
+		return ""
+	}
+	// escaped text is never shorter than raw text, start from the longest candidate and shrink
+	runes := []rune(s)
+	for end := min(len(runes), n-len(ellipsis)); end > 0; end-- {
+		escaped = html.EscapeString(string(runes[:end]))
+		if utf8.RuneCountInString(escaped)+len(ellipsis) <= n {
+			return escaped + ellipsis
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package pushsdk
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	cases := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"longer than ten", 10, "longer ..."},
+		{"RDP 登录成功通知标题", 8, "RDP 登..."},
+		{"登录成功", 2, "登录"},
+		{"abc", 0, ""},
+	}
+	for _, c := range cases {
+		got := truncateString(c.s, c.n)
+		if got != c.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", c.s, c.n, got, c.want)
+		}
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) > max(c.n, 0) {
+			t.Errorf("truncateString(%q, %d) = %q exceeds limit or breaks utf8", c.s, c.n, got)
+		}
+	}
+}
+
+func TestEscapeHTMLWithin(t *testing.T) {
+	cases := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"a&b", 7, "a&amp;b"},
+		{"a&b", 6, "a..."},
+		{"a&b", 3, ""},
+		{"<登录>", 20, "&lt;登录&gt;"},
+		{"<登录>", 9, "&lt;登录..."},
+		{"<登录>", 8, "&lt;登..."},
+		{"<登录>", 6, ""},
+	}
+	for _, c := range cases {
+		if got := escapeHTMLWithin(c.s, c.n); got != c.want {
+			t.Errorf("escapeHTMLWithin(%q, %d) = %q, want %q", c.s, c.n, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package pushsdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+var (
+	ErrInvalidCABundle = errors.New("no valid certificate found in CA bundle")
+
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// HTTPClientConfig describes how providers reach their server
+type HTTPClientConfig struct {
+	// Timeout of a single request, including reading response body, default 30s
+	Timeout Duration `json:"timeout,omitempty" validate:"omitempty,gte=0"`
+	// ProxyURL is used for all requests if set, otherwise proxy is read from environment
+	ProxyURL string `json:"proxyURL,omitempty" validate:"omitempty,url"`
+	// CABundleFile is PEM file with extra CA certificates trusted besides system ones
+	CABundleFile string `json:"caBundleFile,omitempty" validate:"omitempty,file"`
+	// ClientCertFile and ClientKeyFile are PEM files for mTLS
+	ClientCertFile string `json:"clientCertFile,omitempty" validate:"required_with=ClientKeyFile,omitempty,file"`
+	ClientKeyFile  string `json:"clientKeyFile,omitempty" validate:"required_with=ClientCertFile,omitempty,file"`
+	TLSMinVersion  string `json:"tlsMinVersion,omitempty" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+}
+
+// mergeHTTPClientConfig returns base with non-empty fields of override applied, any of them could be nil
+func mergeHTTPClientConfig(base *HTTPClientConfig, override *HTTPClientConfig) *HTTPClientConfig {
+	res := &HTTPClientConfig{}
+	for _, v := range []*HTTPClientConfig{base, override} {
+		if v == nil {
+			continue
+		}
+		if v.Timeout != 0 {
+			res.Timeout = v.Timeout
+		}
+		if v.ProxyURL != "" {
+			res.ProxyURL = v.ProxyURL
+		}
+		if v.CABundleFile != "" {
+			res.CABundleFile = v.CABundleFile
+		}
+		if v.ClientCertFile != "" {
+			res.ClientCertFile = v.ClientCertFile
+			res.ClientKeyFile = v.ClientKeyFile
+		}
+		if v.TLSMinVersion != "" {
+			res.TLSMinVersion = v.TLSMinVersion
+		}
+	}
+	return res
+}
+
+// NewHTTPClient builds a dedicated http.Client from conf, conf could be nil for defaults
+func NewHTTPClient(conf *HTTPClientConfig) (*http.Client, error) {
+	if conf == nil {
+		conf = &HTTPClientConfig{}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if conf.ProxyURL != "" {
+		proxyURL, err := url.Parse(conf.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	tlsConf, err := NewTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConf
+	return &http.Client{
+		Transport: &customUserAgentRT{UserAgent: customUserAgent, Base: transport},
+		Timeout:   conf.timeoutOrDefault(),
+	}, nil
+}
+
+func (conf *HTTPClientConfig) timeoutOrDefault() time.Duration {
+	if conf == nil || conf.Timeout <= 0 {
+		return defaultHTTPTimeout
+	}
+	return time.Duration(conf.Timeout)
+}
+
+// NewTLSConfig builds tls.Config from TLS related fields of conf, also used by non-HTTP providers
+func NewTLSConfig(conf *HTTPClientConfig) (*tls.Config, error) {
+	if conf == nil {
+		conf = &HTTPClientConfig{}
+	}
+	tlsConf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if conf.TLSMinVersion != "" {
+		tlsConf.MinVersion = tlsVersions[conf.TLSMinVersion]
+	}
+	if conf.CABundleFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			// system pool is not available on some platforms, trust bundle only
+			pool = x509.NewCertPool()
+		}
+		pemData, err := os.ReadFile(conf.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCABundle, conf.CABundleFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if conf.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.ClientCertFile, conf.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
@@ -0,0 +1,103 @@
+package pushsdk
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientCABundle(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, err := NewHTTPClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(srv.URL); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("server of unknown CA should be rejected, got %v", err)
+	}
+
+	client, err = NewHTTPClient(&HTTPClientConfig{CABundleFile: caFile})
+	if err != nil {
+		t.Fatalf("new client with ca bundle: %v", err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get with ca bundle: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// TLS 1.3 only client still talks to the server
+	client, err = NewHTTPClient(&HTTPClientConfig{CABundleFile: caFile, TLSMinVersion: "1.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get with TLS 1.3: %v", err)
+	}
+	if resp.TLS == nil || resp.TLS.Version != tls.VersionTLS13 {
+		t.Errorf("negotiated TLS version %v", resp.TLS)
+	}
+	_ = resp.Body.Close()
+
+	badFile := filepath.Join(t.TempDir(), "bad.pem")
+	if err = os.WriteFile(badFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewHTTPClient(&HTTPClientConfig{CABundleFile: badFile}); !errors.Is(err, ErrInvalidCABundle) {
+		t.Errorf("bad ca bundle: got %v", err)
+	}
+	if _, err = NewHTTPClient(&HTTPClientConfig{CABundleFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Errorf("missing ca bundle should fail")
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	var gotHost, gotURL, gotUA string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotURL, gotUA = r.Host, r.RequestURI, r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(&HTTPClientConfig{ProxyURL: proxy.URL, Timeout: Duration(5 * time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("timeout = %s", client.Timeout)
+	}
+	resp, err := client.Get("http://alerts.example.invalid/push?x=1")
+	if err != nil {
+		t.Fatalf("get via proxy: %v", err)
+	}
+	_ = resp.Body.Close()
+	// request line of a proxied request carries absolute URL
+	if gotHost != "alerts.example.invalid" || gotURL != "http://alerts.example.invalid/push?x=1" {
+		t.Errorf("proxy got host %q, request URI %q", gotHost, gotURL)
+	}
+	if gotUA != customUserAgent {
+		t.Errorf("user agent = %q", gotUA)
+	}
+
+	if _, err = NewHTTPClient(&HTTPClientConfig{ProxyURL: "http://[::1"}); err == nil {
+		t.Errorf("invalid proxy url should fail")
+	}
+	client, _ = NewHTTPClient(nil)
+	if client.Timeout != defaultHTTPTimeout {
+		t.Errorf("default timeout = %s", client.Timeout)
+	}
+}
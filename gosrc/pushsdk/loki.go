@@ -0,0 +1,190 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// lokiLabelNameRegex is label name rule of Prometheus data model, names starting with __ are reserved for internal use
+	lokiLabelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// lokiReservedLabels are set by FromGeneral, static labels must not override them
+	lokiReservedLabels = map[string]bool{"job": true, "host": true, "user": true, "event_type": true, "severity": true}
+)
+
+// lokiPushContent is a single stream with single entry, check: https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+type lokiPushContent struct {
+	Streams []lokiStream `json:"streams" validate:"required,min=1"`
+
+	staticLabels map[string]string
+	// provider info
+	providerName PushProvider
+}
+
+// lokiStream values are [timestamp in nanoseconds, log line] pairs
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (lpc *lokiPushContent) Init() {
+	lpc.SetPushProvider()
+}
+
+func (lpc *lokiPushContent) Provider() PushProvider {
+	return lpc.providerName
+}
+
+func (lpc *lokiPushContent) SetPushProvider() {
+	lpc.providerName = Loki
+}
+
+func (lpc *lokiPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*lokiPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	lpc.staticLabels = d1.Labels
+}
+
+// FromGeneral labels stream with host, user and event type, other info stays in the json log line
+// to keep label cardinality low
+func (lpc *lokiPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	ev := newAlertEvent(g)
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{"job": "rdpalert"}
+	for k, v := range lpc.staticLabels {
+		labels[k] = v
+	}
+	for k, v := range map[string]string{"host": ev.Host, "user": ev.User, "event_type": ev.EventType, "severity": string(ev.Severity)} {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	lpc.Streams = []lokiStream{{
+		Stream: labels,
+		Values: [][2]string{{strconv.FormatInt(ev.Timestamp.UnixNano(), 10), string(line)}},
+	}}
+	return lpc, nil
+}
+
+func (lpc *lokiPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(lpc)
+}
+
+func init() {
+	err := verifier.RegisterValidation("lokilabel", func(fl validator.FieldLevel) bool {
+		name := fl.Field().String()
+		return lokiLabelNameRegex.MatchString(name) && !strings.HasPrefix(name, "__") && !lokiReservedLabels[name]
+	})
+	if err != nil {
+		panic(err)
+	}
+	MustRegisterProvider(Loki, func() PushProviderImpl { return &lokiPushProvider{} })
+}
+
+type lokiPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is base URL of Loki or gateway, https://loki.corp.local:3100, /loki/api/v1/push is appended
+	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
+	ExtraParams       *lokiPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (l lokiPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(l)
+	if err1 != nil {
+		return err1
+	}
+	if l.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(l.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (l lokiPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	lpc := &lokiPushContent{}
+	lpc.Init()
+	lpc.AcceptExtParamSettings(l.ExtraParams)
+	return lpc.FromGeneral(g)
+}
+
+func (l lokiPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*lokiPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Content-Type": {postJSONContentType}}
+	secrets := make([]string, 0, 1)
+	if l.ExtraParams != nil {
+		switch {
+		case l.ExtraParams.AccessToken != "":
+			header.Set("Authorization", "Bearer "+l.ExtraParams.AccessToken)
+			secrets = append(secrets, l.ExtraParams.AccessToken)
+		case l.ExtraParams.Username != "":
+			header.Set("Authorization", basicAuthHeader(l.ExtraParams.Username, l.ExtraParams.Password))
+			secrets = append(secrets, l.ExtraParams.Password)
+		}
+		if l.ExtraParams.TenantID != "" {
+			header.Set("X-Scope-OrgID", l.ExtraParams.TenantID)
+		}
+	}
+	_, statusCode, err := l.SendRequest(ctx, Loki, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     strings.TrimSuffix(l.ProviderServerURL, "/") + "/loki/api/v1/push",
+		Header:  header,
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseLokiError(err)
+	}
+	// loki replies 204 without body, timestamp of entry is what to look for in LogQL
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   "Entry accepted",
+		Timestamp: time.Now().Unix(),
+		Ack:       pData.Streams[0].Values[0][0],
+	}, nil
+}
+
+// parseLokiError turns error response into *ProviderError, loki replies plain text reason
+func parseLokiError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	return &ProviderError{
+		Provider: Loki,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     statusErr.StatusCode,
+		Message:  strings.TrimSpace(string(statusErr.Body)),
+		Err:      err,
+	}
+}
+
+type lokiPushProviderExtraParams struct {
+	// TenantID is sent in X-Scope-OrgID for multi-tenant Loki
+	TenantID string `json:"tenantID,omitempty" validate:"omitempty"`
+	// AccessToken is sent as Bearer token, Username and Password are used for basic auth instead, e.g. Grafana Cloud
+	AccessToken string `json:"accessToken,omitempty" validate:"omitempty,excluded_with=Username"`
+	Username    string `json:"username,omitempty" validate:"omitempty"`
+	Password    string `json:"password,omitempty" validate:"required_with=Username"`
+	// Labels are added to every stream, keep them static to avoid high cardinality,
+	// names follow Prometheus rule and can't be one of lokiReservedLabels
+	Labels map[string]string `json:"labels,omitempty" validate:"omitempty,dive,keys,lokilabel,endkeys,required"`
+}
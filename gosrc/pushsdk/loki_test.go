@@ -0,0 +1,94 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLokiLabelsValidation(t *testing.T) {
+	cases := []struct {
+		labels map[string]string
+		valid  bool
+	}{
+		{map[string]string{"env": "prod", "_site": "dc1", "team_2": "sec"}, true},
+		{map[string]string{"2env": "prod"}, false},
+		{map[string]string{"env-name": "prod"}, false},
+		{map[string]string{"env.name": "prod"}, false},
+		{map[string]string{"": "prod"}, false},
+		{map[string]string{"__name__": "x"}, false},
+		{map[string]string{"job": "other"}, false},
+		{map[string]string{"host": "x"}, false},
+		{map[string]string{"severity": "x"}, false},
+		{map[string]string{"env": ""}, false},
+	}
+	for _, c := range cases {
+		prv := lokiPushProvider{ProviderServerURL: "http://loki.local:3100", ExtraParams: &lokiPushProviderExtraParams{Labels: c.labels}}
+		err := prv.VerifyConfig()
+		if (err == nil) != c.valid {
+			t.Errorf("labels %v: verify config = %v, want valid %v", c.labels, err, c.valid)
+		}
+	}
+}
+
+func TestLokiPush(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	prv := &lokiPushProvider{
+		ProviderCommon:    noRetry(),
+		ProviderServerURL: srv.URL,
+		ExtraParams: &lokiPushProviderExtraParams{
+			TenantID: "t1",
+			Username: "123456",
+			Password: "glc_secret",
+			Labels:   map[string]string{"env": "prod"},
+		},
+	}
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+		Title:     "RDP Login - Success",
+		EventType: EventRDPLoginSuccess,
+		Severity:  SeverityWarning,
+		Fields:    []PushField{{Name: FieldHost, Value: "WIN-SRV01"}},
+	})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	resp, err := prv.SendPushContent(context.Background(), spc)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got.URL.Path != "/loki/api/v1/push" {
+		t.Errorf("request path = %q", got.URL.Path)
+	}
+	if user, pass, ok := got.BasicAuth(); !ok || user != "123456" || pass != "glc_secret" {
+		t.Errorf("basic auth = %q %q %v", user, pass, ok)
+	}
+	if v := got.Header.Get("X-Scope-OrgID"); v != "t1" {
+		t.Errorf("X-Scope-OrgID = %q", v)
+	}
+	lpc := &lokiPushContent{}
+	if err := json.Unmarshal(body, lpc); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	labels := lpc.Streams[0].Stream
+	for k, v := range map[string]string{"job": "rdpalert", "env": "prod", "host": "WIN-SRV01", "severity": string(SeverityWarning)} {
+		if labels[k] != v {
+			t.Errorf("label %s = %q, want %q", k, labels[k], v)
+		}
+	}
+	if resp.Ack != lpc.Streams[0].Values[0][0] {
+		t.Errorf("ack = %q, want timestamp of entry", resp.Ack)
+	}
+}
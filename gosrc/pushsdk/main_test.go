@@ -0,0 +1,128 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"rdpalert/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMain sets up the logger that providers and retry policy write to
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "pushsdk-test")
+	if err != nil {
+		panic(err)
+	}
+	err = utils.InitLogger(filepath.Join(dir, "test.log"), "Test ")
+	if err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	_ = utils.DestoryLoggerInstance()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// noRetry lets a provider under test give up after the first attempt
+func noRetry() ProviderCommon {
+	return ProviderCommon{Retry: &RetryPolicy{MaxAttempts: 1}}
+}
+
+// newTestCertificate makes a self-signed certificate of localhost for fake servers,
+// caFile is its PEM to be used as HTTPClientConfig.CABundleFile
+func newTestCertificate(t *testing.T) (cert tls.Certificate, caFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile = filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+// fakePushContent carries general content to fakeProvider as is
+type fakePushContent struct {
+	g *GeneralPushContent
+}
+
+func (f *fakePushContent) Init()                      {}
+func (f *fakePushContent) Provider() PushProvider     { return "fake" }
+func (f *fakePushContent) SetPushProvider()           {}
+func (f *fakePushContent) AcceptExtParamSettings(any) {}
+func (f *fakePushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	f.g = g
+	return f, nil
+}
+func (f *fakePushContent) ToBytes() ([]byte, error) { return []byte(f.g.Title), nil }
+
+// fakeProvider fails with err if set, delay keeps it busy to observe concurrency
+type fakeProvider struct {
+	delay time.Duration
+
+	mu         sync.Mutex
+	err        error
+	calls      int
+	running    int
+	maxRunning int
+}
+
+func (f *fakeProvider) VerifyConfig() error { return nil }
+
+func (f *fakeProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	return (&fakePushContent{}).FromGeneral(g)
+}
+
+func (f *fakeProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	f.mu.Lock()
+	f.calls++
+	f.running++
+	f.maxRunning = max(f.maxRunning, f.running)
+	err := f.err
+	f.mu.Unlock()
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	f.running--
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{Code: 200, Message: "ok", Timestamp: time.Now().Unix()}, nil
+}
+
+func (f *fakeProvider) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
@@ -0,0 +1,215 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// matrixPushContent is content of m.room.message event, rooms must not be end-to-end encrypted
+type matrixPushContent struct {
+	MsgType       string `json:"msgtype" validate:"required,oneof=m.text m.notice"`
+	Body          string `json:"body" validate:"required"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+
+	occurredAt time.Time
+	// delivered are rooms reached by previous attempt, they are skipped
+	delivered []string
+	// provider info
+	providerName PushProvider
+}
+
+func (mpc *matrixPushContent) Init() {
+	mpc.MsgType = "m.notice"
+	mpc.Format = "org.matrix.custom.html"
+	mpc.SetPushProvider()
+}
+
+func (mpc *matrixPushContent) Provider() PushProvider {
+	return mpc.providerName
+}
+
+func (mpc *matrixPushContent) SetPushProvider() {
+	mpc.providerName = Matrix
+}
+
+func (mpc *matrixPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*matrixPushProviderExtraParams)
+	if d1.MsgType != "" {
+		mpc.MsgType = d1.MsgType
+	}
+}
+
+// FromGeneral renders plain body for clients without HTML support, login info is escaped in formatted body
+func (mpc *matrixPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	mpc.occurredAt = g.OccurredAtOrNow()
+	mpc.delivered = g.DeliveredTargets()
+	mpc.Body = g.Title + "\n" + g.Description
+	buf := &strings.Builder{}
+	buf.WriteString("<b>" + html.EscapeString(g.Title) + "</b><br>")
+	if len(g.Fields) == 0 {
+		buf.WriteString(strings.ReplaceAll(html.EscapeString(strings.TrimSpace(g.Description)), "\n", "<br>"))
+	} else {
+		buf.WriteString(html.EscapeString(g.ShortTitle) + "<ul>")
+		for _, v := range g.Fields {
+			buf.WriteString("<li><b>" + html.EscapeString(v.Name) + "</b>: " + html.EscapeString(v.Value) + "</li>")
+		}
+		buf.WriteString("</ul>")
+	}
+	mpc.FormattedBody = buf.String()
+	return mpc, nil
+}
+
+func (mpc *matrixPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(mpc)
+}
+
+// txnID is derived from room, content and time of login, so retries and outbox flush reuse the same one,
+// homeserver then replies the event already sent instead of sending it again
+func (mpc *matrixPushContent) txnID(roomID string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(roomID + "\x00"))
+	h.Write(body)
+	h.Write([]byte("\x00" + mpc.occurredAt.UTC().Format(time.RFC3339Nano)))
+	return "rdpalert-" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func init() {
+	MustRegisterProvider(Matrix, func() PushProviderImpl { return &matrixPushProvider{} })
+}
+
+type matrixPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is homeserver base URL, e.g. https://matrix.corp.local
+	ProviderServerURL string                         `json:"serverURL" validate:"url,required"`
+	ExtraParams       *matrixPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (m matrixPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(m)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(m.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (m matrixPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	mpc := &matrixPushContent{}
+	mpc.Init()
+	mpc.AcceptExtParamSettings(m.ExtraParams)
+	return mpc.FromGeneral(g)
+}
+
+// SendPushContent sends event to every configured room, failure of one room doesn't stop the others,
+// *PartialDeliveryError tells which rooms are reached if some of them failed
+func (m matrixPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*matrixPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	sent := make([]string, 0, len(m.ExtraParams.RoomIDs))
+	delivered := slices.Clone(pData.delivered)
+	errs := make([]error, 0)
+	for _, roomID := range m.ExtraParams.RoomIDs {
+		if slices.Contains(pData.delivered, roomID) {
+			continue
+		}
+		apiURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", strings.TrimSuffix(m.ProviderServerURL, "/"),
+			url.PathEscape(roomID), url.PathEscape(pData.txnID(roomID, body)))
+		respData, _, err := m.SendRequest(ctx, Matrix, &HTTPRequest{
+			Method: http.MethodPut,
+			URL:    apiURL,
+			Header: http.Header{
+				"Content-Type":  {postJSONContentType},
+				"Authorization": {"Bearer " + m.ExtraParams.AccessToken},
+			},
+			Body:       body,
+			RetryAfter: parseMatrixRetryAfter,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", roomID, parseMatrixError(err)))
+			continue
+		}
+		mpr := &matrixPushResponse{}
+		err = json.Unmarshal(respData, mpr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", roomID, err))
+			continue
+		}
+		sent = append(sent, mpr.EventID)
+		delivered = append(delivered, roomID)
+	}
+	if len(errs) != 0 {
+		if len(delivered) != 0 {
+			return nil, &PartialDeliveryError{Delivered: delivered, Err: errors.Join(errs...)}
+		}
+		return nil, errors.Join(errs...)
+	}
+	return &PushResponse{
+		Code:      http.StatusOK,
+		Message:   "Sent EventIDs: " + strings.Join(sent, ", "),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+type matrixPushResponse struct {
+	EventID string `json:"event_id"`
+}
+
+// matrixErrorResponse is the standard error body, check: https://spec.matrix.org/latest/client-server-api/#standard-error-response
+type matrixErrorResponse struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms,omitempty"`
+}
+
+// parseMatrixRetryAfter prefers retry_after_ms in body of M_LIMIT_EXCEEDED
+func parseMatrixRetryAfter(header http.Header, body []byte) time.Duration {
+	mer := &matrixErrorResponse{}
+	if json.Unmarshal(body, mer) == nil && mer.RetryAfterMS > 0 {
+		return time.Duration(mer.RetryAfterMS) * time.Millisecond
+	}
+	return parseRetryAfter(header.Get("Retry-After"))
+}
+
+// parseMatrixError turns error response into *ProviderError by errcode
+func parseMatrixError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	mer := &matrixErrorResponse{}
+	_ = json.Unmarshal(statusErr.Body, mer)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch mer.ErrCode {
+	case "M_UNKNOWN_TOKEN", "M_MISSING_TOKEN", "M_FORBIDDEN":
+		kind = ErrAuthFailed
+	case "M_LIMIT_EXCEEDED":
+		kind = ErrRateLimited
+	}
+	return &ProviderError{Provider: Matrix, Kind: kind, Code: statusErr.StatusCode, Message: strings.TrimSpace(mer.ErrCode + " " + mer.Error), Err: err}
+}
+
+type matrixPushProviderExtraParams struct {
+	// AccessToken is of the bot user, which must have joined the rooms
+	AccessToken string `json:"accessToken" validate:"required"`
+	// RoomIDs are internal room IDs like !abcdefg:matrix.org, aliases are not accepted
+	RoomIDs []string `json:"roomIDs" validate:"required,min=1,dive,startswith=!,contains=:"`
+	// MsgType is m.notice by default, which is not supposed to trigger other bots
+	MsgType string `json:"msgType,omitempty" validate:"omitempty,oneof=m.text m.notice"`
+}
@@ -0,0 +1,181 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// matrixRequest is a send event call received by fakeHomeserver
+type matrixRequest struct {
+	method string
+	roomID string
+	txnID  string
+	auth   string
+	event  *matrixPushContent
+}
+
+// fakeHomeserver replies 502 to the first failures[roomID] sends of a room, rooms in forbidden get M_FORBIDDEN
+type fakeHomeserver struct {
+	mu        sync.Mutex
+	failures  map[string]int
+	forbidden map[string]bool
+	got       []matrixRequest
+}
+
+func (f *fakeHomeserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /_matrix/client/v3/rooms/{roomID}/send/m.room.message/{txnID}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/_matrix/client/v3/rooms/"), "/")
+	if len(parts) != 4 || parts[1] != "send" || parts[2] != "m.room.message" {
+		http.NotFound(w, r)
+		return
+	}
+	event := &matrixPushContent{}
+	_ = json.NewDecoder(r.Body).Decode(event)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	roomID, txnID := parts[0], parts[3]
+	f.got = append(f.got, matrixRequest{method: r.Method, roomID: roomID, txnID: txnID, auth: r.Header.Get("Authorization"), event: event})
+	if f.forbidden[roomID] {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"User @rdpalert:example.org not in room"}`))
+		return
+	}
+	if f.failures[roomID] > 0 {
+		f.failures[roomID]--
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`<html><body>502 Bad Gateway</body></html>`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"event_id":"$` + txnID + `"}`))
+}
+
+func (f *fakeHomeserver) received() []matrixRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := f.got
+	f.got = nil
+	return res
+}
+
+func newTestMatrix(url string, common ProviderCommon) *matrixPushProvider {
+	return &matrixPushProvider{ProviderCommon: common, ProviderServerURL: url, ExtraParams: &matrixPushProviderExtraParams{
+		AccessToken: "syt_token",
+		RoomIDs:     []string{"!a:example.org", "!b:example.org"},
+	}}
+}
+
+func sendMatrix(t *testing.T, prv *matrixPushProvider, g *GeneralPushContent) error {
+	t.Helper()
+	spc, err := prv.TransformToSpecificPushContent(g)
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	_, err = prv.SendPushContent(context.Background(), spc)
+	return err
+}
+
+func testMatrixContent() *GeneralPushContent {
+	g := &GeneralPushContent{
+		Title:      "RDP Login - Success",
+		ShortTitle: "<alice> from 10.0.0.8",
+		OccurredAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		Fields:     []PushField{{Name: FieldUser, Value: `corp\<script>`}},
+	}
+	g.SetSpecificPushProvider(Matrix)
+	return g
+}
+
+func TestMatrixSendEvent(t *testing.T) {
+	hs := &fakeHomeserver{failures: map[string]int{"!b:example.org": 1}}
+	srv := httptest.NewServer(hs)
+	defer srv.Close()
+	prv := newTestMatrix(srv.URL, ProviderCommon{Retry: &RetryPolicy{
+		MaxAttempts:          2,
+		BaseDelay:            ptrTo(Duration(time.Millisecond)),
+		RetryableStatusCodes: []int{http.StatusBadGateway},
+	}})
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	if err := sendMatrix(t, prv, testMatrixContent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	got := hs.received()
+	if len(got) != 3 {
+		t.Fatalf("requests = %d, want 3", len(got))
+	}
+	for _, v := range got {
+		if v.method != http.MethodPut || v.auth != "Bearer syt_token" || !strings.HasPrefix(v.txnID, "rdpalert-") {
+			t.Errorf("request %s %s %s, auth %q", v.method, v.roomID, v.txnID, v.auth)
+		}
+	}
+	if got[0].roomID != "!a:example.org" || got[1].roomID != "!b:example.org" || got[2].roomID != "!b:example.org" {
+		t.Errorf("rooms = %s, %s, %s", got[0].roomID, got[1].roomID, got[2].roomID)
+	}
+	if got[1].txnID != got[2].txnID {
+		t.Errorf("txnID changed on retry: %s, %s", got[1].txnID, got[2].txnID)
+	}
+	if got[0].txnID == got[1].txnID {
+		t.Errorf("rooms share txnID %s", got[0].txnID)
+	}
+
+	event := got[0].event
+	if event.MsgType != "m.notice" || event.Format != "org.matrix.custom.html" {
+		t.Errorf("msgtype %q format %q", event.MsgType, event.Format)
+	}
+	wantHTML := `<b>RDP Login - Success</b><br>&lt;alice&gt; from 10.0.0.8<ul><li><b>User</b>: corp\&lt;script&gt;</li></ul>`
+	if event.FormattedBody != wantHTML {
+		t.Errorf("formatted_body = %s\nwant %s", event.FormattedBody, wantHTML)
+	}
+	if strings.Contains(event.Body, "<b>") {
+		t.Errorf("plain body has markup: %q", event.Body)
+	}
+
+	// another run of the same alert, e.g. outbox flush, reuses txnID so homeserver dedups it
+	if err := sendMatrix(t, prv, testMatrixContent()); err != nil {
+		t.Fatalf("send again: %v", err)
+	}
+	again := hs.received()
+	if len(again) != 2 || again[0].txnID != got[0].txnID || again[1].txnID != got[1].txnID {
+		t.Errorf("txnIDs of the same alert changed")
+	}
+}
+
+func TestMatrixReplayOnlyFailedRooms(t *testing.T) {
+	hs := &fakeHomeserver{forbidden: map[string]bool{"!b:example.org": true}}
+	srv := httptest.NewServer(hs)
+	defer srv.Close()
+	prv := newTestMatrix(srv.URL, noRetry())
+	g := testMatrixContent()
+
+	var partialErr *PartialDeliveryError
+	if err := sendMatrix(t, prv, g); !errors.As(err, &partialErr) {
+		t.Fatalf("want *PartialDeliveryError, got %v", err)
+	}
+	if !errors.Is(partialErr, ErrAuthFailed) {
+		t.Errorf("failed room should keep its provider error: %v", partialErr)
+	}
+	if !slices.Equal(partialErr.Delivered, []string{"!a:example.org"}) {
+		t.Errorf("delivered = %v", partialErr.Delivered)
+	}
+	first := hs.received()
+
+	// replay as outbox does, only the failed room should be sent again with the same txnID
+	hs.forbidden = nil
+	g.Delivered = map[PushProvider][]string{Matrix: partialErr.Delivered}
+	if err := sendMatrix(t, prv, g); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	got := hs.received()
+	if len(got) != 1 || got[0].roomID != "!b:example.org" || got[0].txnID != first[1].txnID {
+		t.Errorf("replay sent %v, want only !b:example.org with txnID %s", got, first[1].txnID)
+	}
+}
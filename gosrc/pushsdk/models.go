@@ -1,11 +1,17 @@
 package pushsdk
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
+	"net/http"
 	"rdpalert/utils"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,6 +20,7 @@ var (
 	//ErrConfigLogicMismatch    = errors.New("config logic mismatch, required item is not in place")
 	ErrGPCIsNotSet            = errors.New("general push content is not staged")
 	ErrPushMethodNotSupported = errors.New("push method not supported")
+	ErrRateLimited            = errors.New("rate limited by push service")
 )
 
 type PushContent interface {
@@ -31,17 +38,138 @@ type GeneralPushContent struct {
 	Description  string         `json:"description"`
 	ExtParams    map[string]any `json:"ext_params"`
 	TagsOrGroups []string       `json:"tags_or_groups"`
+	Severity     Severity       `json:"severity,omitempty"`
+	// Fields are structured details of the alert in display order, Description holds the same info in text
+	Fields []PushField `json:"fields,omitempty"`
+	// EventType picks per-event settings of providers, e.g. EventRDPLoginSuccess
+	EventType string `json:"event_type,omitempty"`
+	// OccurredAt is when the login happened, kept as-is when delivery is retried from outbox
+	OccurredAt time.Time `json:"occurred_at,omitempty"`
+	// Delivered lists targets already reached by providers sending to several targets, e.g. chat IDs of Telegram,
+	// outbox keeps it so that replay only sends to the failed ones
+	Delivered    map[PushProvider][]string `json:"delivered,omitempty"`
 	providerName PushProvider
 }
 
+// PushField is a labelled detail of the alert, e.g. user or source IP
+type PushField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// well-known EventType values
+const (
+	EventRDPLoginSuccess = "rdpLoginSuccess"
+)
+
+// well-known PushField names, providers look them up to build structured messages
+const (
+	FieldSourceIP = "Source IP"
+	FieldUser     = "User"
+	FieldDomain   = "Domain"
+	FieldHost     = "Host"
+	FieldHostIPs  = "Host IPs"
+)
+
+// Field returns value of field name, empty if not present
+func (gpc *GeneralPushContent) Field(name string) string {
+	for _, v := range gpc.Fields {
+		if v.Name == name {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+// OccurredAtOrNow returns OccurredAt, current time if not set
+func (gpc *GeneralPushContent) OccurredAtOrNow() time.Time {
+	if gpc.OccurredAt.IsZero() {
+		return time.Now()
+	}
+	return gpc.OccurredAt
+}
+
+// DedupKey identifies logins of same user from same source into same host,
+// incident services use it to group repeated alerts instead of paging again
+func (gpc *GeneralPushContent) DedupKey() string {
+	h := sha256.Sum256([]byte(strings.Join([]string{gpc.Field(FieldHost), gpc.Field(FieldUser), gpc.Field(FieldSourceIP)}, "\x00")))
+	return "rdpalert-" + hex.EncodeToString(h[:16])
+}
+
 func (gpc *GeneralPushContent) SetSpecificPushProvider(p PushProvider) {
 	gpc.providerName = p
 }
 
+// DeliveredTargets returns targets of current provider reached by a previous attempt
+func (gpc *GeneralPushContent) DeliveredTargets() []string {
+	return gpc.Delivered[gpc.providerName]
+}
+
+// SeverityOrDefault returns Severity, SeverityInfo if not set
+func (gpc *GeneralPushContent) SeverityOrDefault() Severity {
+	if gpc.Severity == "" {
+		return SeverityInfo
+	}
+	return gpc.Severity
+}
+
+// Severity of an alert, providers map it to their own priority or level
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
+	SeverityCritical Severity = "critical"
+)
+
+// severityColors are RGB colors used by providers with colored messages
+var severityColors = map[Severity]int{
+	SeverityInfo:     0x3498DB,
+	SeverityWarning:  0xF39C12,
+	SeverityError:    0xE74C3C,
+	SeverityCritical: 0x992D22,
+}
+
+// Color returns RGB color of severity, color of SeverityInfo for unknown one
+func (s Severity) Color() int {
+	c, exists := severityColors[s]
+	if !exists {
+		return severityColors[SeverityInfo]
+	}
+	return c
+}
+
 // PushConfig stored user-defined required configuration
 type PushConfig struct {
-	PushMethods map[PushProvider]json.RawMessage `json:"pushMethods" validate:"required"`
-	IsDryRun    bool                             `json:"isDryRun"`
+	PushMethods map[PushProvider]json.RawMessage `json:"pushMethods" validate:"required_without=NotifyURLs"`
+	// NotifyURLs configure push methods in one line each, e.g. bark://key@api.day.app/group, check notifyURLSchemes
+	NotifyURLs []string `json:"notifyURLs,omitempty" validate:"omitempty,dive,required,contains=://"`
+	IsDryRun   bool     `json:"isDryRun"`
+	// MaxConcurrency limits how many providers are sent in parallel, 0 means defaultMaxConcurrency
+	MaxConcurrency int `json:"maxConcurrency,omitempty" validate:"omitempty,gte=0"`
+	// Outbox controls spool of undelivered alerts
+	Outbox *OutboxConfig `json:"outbox,omitempty" validate:"omitempty"`
+	// Retry is the default retry policy of all providers, provider could override it in its own config
+	Retry *RetryPolicy `json:"retry,omitempty" validate:"omitempty"`
+	// HTTPClient is the default http client setting of all providers, provider could override it in its own config
+	HTTPClient *HTTPClientConfig `json:"httpClient,omitempty" validate:"omitempty"`
+	// DeliveryDeadline bounds the whole run including outbox flush, default 5m
+	DeliveryDeadline Duration `json:"deliveryDeadline,omitempty" validate:"omitempty,gte=0"`
+}
+
+const (
+	defaultMaxConcurrency   = 4
+	defaultDeliveryDeadline = 5 * time.Minute
+)
+
+// DeliveryContext derives a context from parent bounded by DeliveryDeadline
+func (pc *PushConfig) DeliveryContext(parent context.Context) (context.Context, context.CancelFunc) {
+	deadline := time.Duration(pc.DeliveryDeadline)
+	if deadline <= 0 {
+		deadline = defaultDeliveryDeadline
+	}
+	return context.WithTimeout(parent, deadline)
 }
 
 func (pc *PushConfig) VerifyConfig() error {
@@ -49,7 +177,15 @@ func (pc *PushConfig) VerifyConfig() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	// make sure every configured method has a registered provider
+	for k := range pc.PushMethods {
+		_, err = newProviderInstance(k)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = pc.notifyURLProviders()
+	return err
 }
 
 // PushProvider records legit and supported push service provider
@@ -60,10 +196,133 @@ const (
 	BarkForiOS PushProvider = "bark"
 	// ServChan3 stands for ServChan3 offered by EasyChen, check: https://sc3.ft07.com
 	ServChan3 PushProvider = "sc3"
-	//ServChanTurbo   PushProvider = "sct"     // not supported yet
+	// ServChanTurbo stands for ServChan Turbo offered by EasyChen, check: https://sct.ftqq.com
+	ServChanTurbo PushProvider = "sct"
+	// Webhook stands for any HTTP endpoint, request is rendered from user-defined template
+	Webhook PushProvider = "webhook"
+	// Ntfy stands for ntfy.sh or self-hosted ntfy server, check: https://docs.ntfy.sh/publish/
+	Ntfy PushProvider = "ntfy"
+	// Telegram stands for Telegram Bot API, check: https://core.telegram.org/bots/api#sendmessage
+	Telegram PushProvider = "telegram"
+	// Discord stands for Discord channel webhook, check: https://discord.com/developers/docs/resources/webhook
+	Discord PushProvider = "discord"
+	// Slack stands for Slack incoming webhook, check: https://api.slack.com/messaging/webhooks
+	Slack PushProvider = "slack"
+	// MSTeams stands for Microsoft Teams Workflows webhook, message is an adaptive card
+	MSTeams PushProvider = "teams"
+	// GoogleChat stands for Google Chat space webhook, message is a cardV2
+	GoogleChat PushProvider = "gchat"
+	// Email stands for SMTP relay, message is multipart/alternative with text and HTML body
+	Email PushProvider = "email"
+	// Gotify stands for self-hosted Gotify server, check: https://gotify.net/api-docs
+	Gotify PushProvider = "gotify"
+	// PagerDuty stands for PagerDuty Events API v2, check: https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+	PagerDuty PushProvider = "pagerduty"
+	// Opsgenie stands for Opsgenie Alert API, check: https://docs.opsgenie.com/docs/alert-api
+	Opsgenie PushProvider = "opsgenie"
+	// DingTalk stands for DingTalk group robot, check: https://open.dingtalk.com/document/robots/custom-robot-access
+	DingTalk PushProvider = "dingtalk"
+	// Feishu stands for Feishu / Lark group bot, check: https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot
+	Feishu PushProvider = "feishu"
+	// WeCom stands for WeCom (WeChat Work) group robot, check: https://developer.work.weixin.qq.com/document/path/91770
+	WeCom PushProvider = "wecom"
+	// Matrix stands for Matrix client-server API, check: https://spec.matrix.org/latest/client-server-api/#put_matrixclientv3roomsroomidsendeventtypetxnid
+	Matrix PushProvider = "matrix"
+	// Pushover stands for Pushover message API, check: https://pushover.net/api
+	Pushover PushProvider = "pushover"
+	// Pushbullet stands for Pushbullet pushes API, check: https://docs.pushbullet.com/#create-push
+	Pushbullet PushProvider = "pushbullet"
+	// Syslog stands for RFC 5424 syslog over UDP, TCP or TLS, for SIEM ingestion
+	Syslog PushProvider = "syslog"
+	// Splunk stands for Splunk HTTP Event Collector, check: https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+	Splunk PushProvider = "splunk"
+	// Elasticsearch stands for Elasticsearch bulk API, check: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
+	Elasticsearch PushProvider = "elasticsearch"
+	// Loki stands for Grafana Loki push API, check: https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+	Loki PushProvider = "loki"
+	// MQTT stands for MQTT 3.1.1 publisher, check: https://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+	MQTT PushProvider = "mqtt"
 	//generalProvider PushProvider = "general" // used for internal data structure only
 )
 
+// Duration is time.Duration that reads from string like "1.5s" in JSON config
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	d1, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(d1)
+	return nil
+}
+
+// ProviderCommon holds settings shared by all providers, embed it into provider config,
+// pusher fills it with global settings after config is loaded
+type ProviderCommon struct {
+	Retry      *RetryPolicy      `json:"retry,omitempty" validate:"omitempty"`
+	HTTPClient *HTTPClientConfig `json:"httpClient,omitempty" validate:"omitempty"`
+
+	client *http.Client
+}
+
+// Common gives pusher access to embedded ProviderCommon
+func (pc *ProviderCommon) Common() *ProviderCommon {
+	return pc
+}
+
+// InjectHTTPClient sets the client used by this provider for all requests
+func (pc *ProviderCommon) InjectHTTPClient(c *http.Client) {
+	pc.client = c
+}
+
+// Client returns injected http client, nil if nothing is injected
+func (pc *ProviderCommon) Client() *http.Client {
+	return pc.client
+}
+
+// SendRequest sends r with injected http client and retry policy of this provider
+func (pc *ProviderCommon) SendRequest(ctx context.Context, name PushProvider, r *HTTPRequest) ([]byte, int, error) {
+	var (
+		respData   []byte
+		statusCode int
+	)
+	policy := pc.Retry
+	if policy == nil {
+		policy = mergeRetryPolicy(nil, nil)
+	}
+	err := policy.Do(ctx, string(name), func(_ int) error {
+		var err error
+		respData, statusCode, err = SendHttpRequest(ctx, pc.client, r)
+		return err
+	})
+	return respData, statusCode, err
+}
+
+// PostJSON sends body to url with retry policy of this provider, only 200 is accepted
+func (pc *ProviderCommon) PostJSON(ctx context.Context, name PushProvider, url string, body []byte) ([]byte, int, error) {
+	return pc.SendRequest(ctx, name, &HTTPRequest{
+		Method:       http.MethodPost,
+		URL:          url,
+		Header:       http.Header{"Content-Type": {postJSONContentType}},
+		Body:         body,
+		AcceptStatus: func(code int) bool { return code == http.StatusOK },
+	})
+}
+
+type commonSettingsHolder interface {
+	Common() *ProviderCommon
+}
+
 // AbstractPushProvider handle all provider specific issues
 // Only used for skeleton and code framework, didn't implement any actual methods
 type AbstractPushProvider struct {
@@ -74,7 +333,7 @@ type AbstractPushProvider struct {
 type PushProviderImpl interface {
 	VerifyConfig() error
 	TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error)
-	SendPushContent(p PushContent) (*PushResponse, error)
+	SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error)
 }
 
 // PushResponse represent HTTP Response Data from PushNotification Service Provider
@@ -83,10 +342,15 @@ type PushResponse struct {
 	Code      int
 	Message   string
 	Timestamp int64
+	// Ack is ingestion acknowledgement of event sinks, e.g. Splunk ackId or Elasticsearch document ID
+	Ack string
 }
 
 func (p *PushResponse) String() string {
 	t1 := time.Unix(p.Timestamp, 0)
+	if p.Ack != "" {
+		return fmt.Sprintf("Response %d: %s, Ack: %s at time %s", p.Code, p.Message, p.Ack, t1.Format(time.RFC3339))
+	}
 	return fmt.Sprintf("Response %d: %s at time %s", p.Code, p.Message, t1.Format(time.RFC3339))
 }
 
@@ -95,6 +359,11 @@ type pusher struct {
 	Config               *PushConfig
 	GeneralContent       *GeneralPushContent
 	SpecificPushContents []*PushContent
+
+	// providers are instantiated and verified from Config.PushMethods
+	providers map[PushProvider]PushProviderImpl
+	// outbox stores failed content for later delivery, optional
+	outbox *Outbox
 }
 
 // NewPusher will validate config and instantiate push service
@@ -107,77 +376,177 @@ func NewPusher(conf *PushConfig) (*pusher, error) {
 	if err != nil {
 		return nil, err
 	}
+	// providers without their own http client setting share this one
+	sharedClient, err := NewHTTPClient(conf.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	providers := make(map[PushProvider]PushProviderImpl, len(conf.PushMethods)+len(conf.NotifyURLs))
+	for k, v := range conf.PushMethods {
+		prv, err := loadProvider(conf, sharedClient, k, v)
+		if err != nil {
+			return nil, err
+		}
+		providers[k] = prv
+	}
+	urlProviders, err := conf.notifyURLProviders()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range urlProviders {
+		err = setupProvider(conf, sharedClient, k, v)
+		if err != nil {
+			return nil, err
+		}
+		providers[k] = v
+	}
 	return &pusher{
 		Config:               conf,
 		SpecificPushContents: []*PushContent{},
 		GeneralContent:       nil,
+		providers:            providers,
 	}, nil
 }
 
+// loadProvider instantiates provider from registry, then unmarshal and verify its config
+func loadProvider(conf *PushConfig, sharedClient *http.Client, name PushProvider, rawConf json.RawMessage) (PushProviderImpl, error) {
+	prv, err := newProviderInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(rawConf, prv)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal config of %s: %w", name, err)
+	}
+	err = setupProvider(conf, sharedClient, name, prv)
+	if err != nil {
+		return nil, err
+	}
+	return prv, nil
+}
+
+// setupProvider verifies config of prv, then applies global retry policy and http client to it
+func setupProvider(conf *PushConfig, sharedClient *http.Client, name PushProvider, prv PushProviderImpl) error {
+	err := prv.VerifyConfig()
+	if err != nil {
+		return fmt.Errorf("verify config of %s: %w", name, err)
+	}
+	if h, ok := prv.(commonSettingsHolder); ok {
+		c := h.Common()
+		c.Retry = mergeRetryPolicy(conf.Retry, c.Retry)
+		c.InjectHTTPClient(sharedClient)
+		if c.HTTPClient != nil {
+			client, err := NewHTTPClient(mergeHTTPClientConfig(conf.HTTPClient, c.HTTPClient))
+			if err != nil {
+				return fmt.Errorf("http client of %s: %w", name, err)
+			}
+			c.InjectHTTPClient(client)
+		}
+		// keep effective setting for providers not speaking HTTP
+		c.HTTPClient = mergeHTTPClientConfig(conf.HTTPClient, c.HTTPClient)
+	}
+	return nil
+}
+
 func (p *pusher) StageGeneralPushContent(g *GeneralPushContent) {
 	p.GeneralContent = g
 }
 
-func (p *pusher) SendPush() error {
+// SendPush delivers staged content to all configured providers concurrently,
+// a failed provider never stops the others, check the returned report for per-provider results,
+// providers not finished before ctx is done are reported as failed
+func (p *pusher) SendPush(ctx context.Context) (*PushReport, error) {
 	gLogger, err := utils.GetLoggerInstance()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if p.GeneralContent == nil {
-		return ErrGPCIsNotSet
+		return nil, ErrGPCIsNotSet
 	}
+	report := &PushReport{}
 	if p.Config.IsDryRun {
 		gLogger.Info("Config Is Set To DryRun, No HTTP Request will be sent.")
-		return nil
-	}
-	// loop through each provider and instantiate
-	for k, v := range p.Config.PushMethods {
-		switch k {
-		case BarkForiOS:
-			var prv = &barkPushProvider{}
-			err = json.Unmarshal(v, prv)
-			if err != nil {
-				return err
-			}
-			err = prv.VerifyConfig()
-			if err != nil {
-				return err
-			}
-			spc, err := prv.TransformToSpecificPushContent(p.GeneralContent)
-			if err != nil {
-				gLogger.Error("Failed to transform to specific push content: ", err.Error())
-				return err
-			}
-			spr, err := prv.SendPushContent(spc)
-			if err != nil {
-				gLogger.Error("Failed to send push content: ", err.Error())
-				return err
-			}
-			gLogger.Info("Push response received: ", spr.String())
-		case ServChan3:
-			var prv = &sc3PushProvider{}
-			err = json.Unmarshal(v, prv)
-			if err != nil {
-				return err
-			}
-			err = prv.VerifyConfig()
-			if err != nil {
-				return err
-			}
-			spc, err := prv.TransformToSpecificPushContent(p.GeneralContent)
-			if err != nil {
-				gLogger.Error("Failed to transform to specific push content: ", err.Error())
-				return err
-			}
-			spr, err := prv.SendPushContent(spc)
-			if err != nil {
-				gLogger.Error("Failed to send push content: ", err.Error())
-				return err
+		return report, nil
+	}
+	targets := make([]PushProvider, 0, len(p.providers))
+	for k := range p.providers {
+		targets = append(targets, k)
+	}
+	report = p.deliver(ctx, p.GeneralContent, targets)
+	if err = report.Err(); err != nil {
+		p.spoolFailures(p.GeneralContent, report, nil)
+	}
+	return report, err
+}
+
+// deliver sends g to targets concurrently, all targets must be configured providers
+func (p *pusher) deliver(ctx context.Context, g *GeneralPushContent, targets []PushProvider) *PushReport {
+	gLogger, _ := utils.GetLoggerInstance()
+	report := &PushReport{}
+	workers := p.Config.MaxConcurrency
+	if workers <= 0 {
+		workers = defaultMaxConcurrency
+	}
+	if workers > len(targets) {
+		workers = len(targets)
+	}
+	// bounded worker pool, each worker picks next provider from jobs
+	jobs := make(chan PushProvider)
+	results := make(chan *PushResult)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				startT := time.Now()
+				spr, err := sendViaProvider(ctx, k, p.providers[k], g)
+				results <- &PushResult{
+					Provider: k,
+					Response: spr,
+					Latency:  time.Since(startT),
+					Err:      err,
+				}
 			}
-			gLogger.Info("Push response received: ", spr.String())
-		default:
-			return ErrPushMethodNotSupported
+		}()
+	}
+	go func() {
+		for _, k := range targets {
+			jobs <- k
 		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	for res := range results {
+		gLogger.Info("Push result: ", res.String())
+		report.add(res)
 	}
-	return nil
+	return report
+}
+
+// sendViaProvider transforms general content to provider specific content and send it out
+func sendViaProvider(ctx context.Context, name PushProvider, prv PushProviderImpl, g *GeneralPushContent) (*PushResponse, error) {
+	gLogger, err := utils.GetLoggerInstance()
+	if err != nil {
+		return nil, err
+	}
+	// deadline may be exceeded while waiting for a free worker
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	gpc := *g
+	gpc.SetSpecificPushProvider(name)
+	spc, err := prv.TransformToSpecificPushContent(&gpc)
+	if err != nil {
+		gLogger.Error("Failed to transform to specific push content: ", name, err.Error())
+		return nil, err
+	}
+	spr, err := prv.SendPushContent(ctx, spc)
+	if err != nil {
+		gLogger.Error("Failed to send push content: ", name, err.Error())
+		// keep response if service replied with an error code
+		return spr, err
+	}
+	return spr, nil
 }
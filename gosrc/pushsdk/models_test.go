@@ -0,0 +1,75 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeliverMaxConcurrency(t *testing.T) {
+	cases := []struct {
+		maxConcurrency int
+		providers      int
+		want           int
+	}{
+		{1, 5, 1},
+		{2, 5, 2},
+		{0, 8, defaultMaxConcurrency},
+		{6, 3, 3},
+	}
+	for _, c := range cases {
+		// one instance under several names, so it sees how many sends run at the same time
+		prv := &fakeProvider{delay: 20 * time.Millisecond}
+		p := &pusher{Config: &PushConfig{MaxConcurrency: c.maxConcurrency}, providers: map[PushProvider]PushProviderImpl{}}
+		targets := make([]PushProvider, 0, c.providers)
+		for i := 0; i < c.providers; i++ {
+			name := PushProvider(fmt.Sprintf("fake%d", i))
+			p.providers[name] = prv
+			targets = append(targets, name)
+		}
+		report := p.deliver(context.Background(), &GeneralPushContent{Title: "RDP Login - Success"}, targets)
+		if prv.calls != c.providers || prv.maxRunning != c.want {
+			t.Errorf("maxConcurrency %d with %d providers: calls %d, max running %d, want %d",
+				c.maxConcurrency, c.providers, prv.calls, prv.maxRunning, c.want)
+		}
+		if len(report.Results) != c.providers || len(report.Succeeded()) != c.providers {
+			t.Errorf("maxConcurrency %d: report %s", c.maxConcurrency, report.String())
+		}
+	}
+}
+
+func TestDeliverReportsEveryProvider(t *testing.T) {
+	errBroken := errors.New("broken")
+	broken := &fakeProvider{err: errBroken}
+	p := &pusher{Config: &PushConfig{}, providers: map[PushProvider]PushProviderImpl{
+		"fake-a": &fakeProvider{},
+		"fake-b": broken,
+		"fake-c": &fakeProvider{},
+	}}
+	report := p.deliver(context.Background(), &GeneralPushContent{Title: "RDP Login - Success"}, []PushProvider{"fake-c", "fake-b", "fake-a"})
+	if len(report.Results) != 3 {
+		t.Fatalf("results = %d, want one per provider", len(report.Results))
+	}
+	for i, want := range []PushProvider{"fake-a", "fake-b", "fake-c"} {
+		if report.Results[i].Provider != want {
+			t.Errorf("result %d is of %s, want %s", i, report.Results[i].Provider, want)
+		}
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Provider != "fake-b" || !errors.Is(report.Err(), errBroken) {
+		t.Errorf("failed = %v, err %v", failed, report.Err())
+	}
+	if report.Results[0].Response == nil || report.Results[0].Latency <= 0 {
+		t.Errorf("success result misses response or latency: %s", report.Results[0].String())
+	}
+
+	// providers waiting for a worker when ctx is done are reported as failed, not dropped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = p.deliver(ctx, &GeneralPushContent{Title: "RDP Login - Success"}, []PushProvider{"fake-a", "fake-c"})
+	if len(report.Failed()) != 2 || !errors.Is(report.Err(), context.Canceled) {
+		t.Errorf("canceled delivery: %s", report.String())
+	}
+}
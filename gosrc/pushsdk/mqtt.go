@@ -0,0 +1,501 @@
+package pushsdk
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"rdpalert/utils"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMQTTTopic = "rdpalert/{hostname}/login"
+	// mqttKeepAlive is sent in CONNECT, the session never lasts that long
+	mqttKeepAlive = 60
+
+	mqttPacketConnect    = 0x10
+	mqttPacketConnAck    = 0x20
+	mqttPacketPublish    = 0x30
+	mqttPacketPubAck     = 0x40
+	mqttPacketPubRec     = 0x50
+	mqttPacketPubRel     = 0x62
+	mqttPacketPubComp    = 0x70
+	mqttPacketDisconnect = 0xE0
+
+	// mqttPublishDup marks a PUBLISH re-sent in a resumed session
+	mqttPublishDup = 0x08
+)
+
+var (
+	ErrMQTTSchemeNotSupported = errors.New("mqtt server url scheme must be mqtt or mqtts")
+	ErrMQTTInvalidTopic       = errors.New("mqtt topic must not be empty or contain wildcards")
+	ErrMQTTProtocol           = errors.New("mqtt protocol violation")
+)
+
+// mqttTopicEscaper keeps placeholder values from adding topic levels or wildcards
+var mqttTopicEscaper = strings.NewReplacer("/", "_", "+", "_", "#", "_", "\x00", "")
+
+// mqttConnAckErrors explains CONNACK return codes of MQTT 3.1.1
+var mqttConnAckErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// mqttPushContent is a single PUBLISH, Payload is the alert event in json
+type mqttPushContent struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+
+	topicTemplate string
+	// provider info
+	providerName PushProvider
+}
+
+func (mpc *mqttPushContent) Init() {
+	mpc.SetPushProvider()
+	mpc.topicTemplate = defaultMQTTTopic
+}
+
+func (mpc *mqttPushContent) Provider() PushProvider {
+	return mpc.providerName
+}
+
+func (mpc *mqttPushContent) SetPushProvider() {
+	mpc.providerName = MQTT
+}
+
+func (mpc *mqttPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*mqttPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Topic != "" {
+		mpc.topicTemplate = d1.Topic
+	}
+	mpc.QoS = byte(d1.QoS)
+	mpc.Retain = d1.Retain
+}
+
+// FromGeneral renders topic template, {hostname}, {user}, {domain}, {eventType} and {severity} are supported
+func (mpc *mqttPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	hostname := g.Field(FieldHost)
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	topic := strings.NewReplacer(
+		"{hostname}", mqttTopicEscaper.Replace(hostname),
+		"{user}", mqttTopicEscaper.Replace(g.Field(FieldUser)),
+		"{domain}", mqttTopicEscaper.Replace(g.Field(FieldDomain)),
+		"{eventType}", mqttTopicEscaper.Replace(g.EventType),
+		"{severity}", string(g.SeverityOrDefault()),
+	).Replace(mpc.topicTemplate)
+	if topic == "" || strings.ContainsAny(topic, "+#\x00") || len(topic) > 65535 {
+		return nil, fmt.Errorf("%w: %s", ErrMQTTInvalidTopic, topic)
+	}
+	mpc.Topic = topic
+	payload, err := json.Marshal(newAlertEvent(g))
+	if err != nil {
+		return nil, err
+	}
+	mpc.Payload = payload
+	return mpc, nil
+}
+
+// ToBytes returns the application message, packet is encoded by provider as packet ID belongs to session
+func (mpc *mqttPushContent) ToBytes() ([]byte, error) {
+	return mpc.Payload, nil
+}
+
+func init() {
+	MustRegisterProvider(MQTT, func() PushProviderImpl { return &mqttPushProvider{} })
+}
+
+type mqttPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is mqtt://host:1883 or mqtts://host:8883, TLS setting is taken from httpClient
+	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
+	ExtraParams       *mqttPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (m mqttPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(m)
+	if err1 != nil {
+		return err1
+	}
+	serverURL, err := url.Parse(m.ProviderServerURL)
+	if err != nil {
+		return err
+	}
+	switch serverURL.Scheme {
+	case "mqtt", "mqtts":
+	default:
+		return fmt.Errorf("%w: %s", ErrMQTTSchemeNotSupported, serverURL.Scheme)
+	}
+	if m.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(m.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (m mqttPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	mpc := &mqttPushContent{}
+	mpc.Init()
+	mpc.AcceptExtParamSettings(m.ExtraParams)
+	return mpc.FromGeneral(g)
+}
+
+func (m mqttPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*mqttPushContent)
+	payload, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	policy := m.Retry
+	if policy == nil {
+		policy = mergeRetryPolicy(nil, nil)
+	}
+	session := m.newSession(pData.QoS)
+	var ack string
+	err = policy.Do(ctx, string(MQTT), func(_ int) error {
+		var err error
+		ack, err = m.publish(ctx, session, pData, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if session.persistent {
+		// the push is done, don't leave the session on broker
+		err = m.discardSession(ctx, session.clientID)
+		if err != nil {
+			if gLogger, err2 := utils.GetLoggerInstance(); err2 == nil {
+				gLogger.Warn("MQTT: message is published but session is not discarded: ", err.Error())
+			}
+		}
+	}
+	return &PushResponse{
+		Code:      0,
+		Message:   fmt.Sprintf("Published %d bytes to %s with QoS %d", len(payload), pData.Topic, pData.QoS),
+		Timestamp: time.Now().Unix(),
+		Ack:       ack,
+	}, nil
+}
+
+// mqttSession is kept across attempts of a push. QoS 2 runs in a persistent session with the same client ID,
+// a retry resumes the flow with original packet ID so broker won't deliver the message twice,
+// check section 4.4 of spec. QoS 0 and 1 use clean session, a retry of QoS 1 may deliver a duplicate.
+type mqttSession struct {
+	clientID   string
+	persistent bool
+	// connected is set once broker holds the session of this push
+	connected bool
+	// published is set once PUBLISH may have reached broker, released once broker replied PUBREC
+	published bool
+	released  bool
+}
+
+// newSession uses client ID in settings, a random one if not set so concurrent runs won't kick each other
+func (m mqttPushProvider) newSession(qos byte) *mqttSession {
+	s := &mqttSession{persistent: qos == 2}
+	if m.ExtraParams != nil {
+		s.clientID = m.ExtraParams.ClientID
+	}
+	if s.clientID == "" {
+		rb := make([]byte, 6)
+		_, _ = rand.Read(rb)
+		s.clientID = "rdpalert-" + hex.EncodeToString(rb)
+	}
+	return s
+}
+
+// publish runs a single session of MQTT 3.1.1: CONNECT, PUBLISH with its QoS flow and DISCONNECT,
+// check: https://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+func (m mqttPushProvider) publish(ctx context.Context, s *mqttSession, pData *mqttPushContent, payload []byte) (string, error) {
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	r := bufio.NewReader(conn)
+	sessionPresent, err := m.connect(conn, r, s.clientID, !s.persistent)
+	if err != nil {
+		return "", err
+	}
+	if sessionPresent && !s.connected {
+		// session is left by an earlier failed push of the same client ID, it may hold packet ID 1 still
+		s.connected = true
+		_, _ = conn.Write([]byte{mqttPacketDisconnect, 0x00})
+		err = m.discardSession(ctx, s.clientID)
+		if err != nil {
+			return "", err
+		}
+		return m.publish(ctx, s, pData, payload)
+	}
+	s.connected = true
+
+	// packet ID is only present for QoS 1 and 2, it's always 1 as this is the only message of session
+	var packetID uint16 = 1
+	ack := ""
+	switch pData.QoS {
+	case 0, 1:
+		_, err = conn.Write(publishPacket(pData, payload, packetID, false))
+		if err != nil {
+			return "", err
+		}
+		if pData.QoS == 1 {
+			err = expectMQTTAck(r, mqttPacketPubAck, packetID)
+			ack = fmt.Sprintf("PUBACK %d", packetID)
+		}
+	case 2:
+		// broker keeps packet ID of a received PUBLISH until PUBREL, a PUBLISH re-sent before that is only acked,
+		// once PUBREC is received, only PUBREL is re-sent
+		if !s.released {
+			dup := s.published
+			s.published = true
+			_, err = conn.Write(publishPacket(pData, payload, packetID, dup))
+			if err != nil {
+				return "", err
+			}
+			err = expectMQTTAck(r, mqttPacketPubRec, packetID)
+			if err != nil {
+				return "", err
+			}
+			s.released = true
+		}
+		_, err = conn.Write(encodeMQTTPacket(mqttPacketPubRel, binary.BigEndian.AppendUint16(nil, packetID)))
+		if err != nil {
+			return "", err
+		}
+		err = expectMQTTAck(r, mqttPacketPubComp, packetID)
+		ack = fmt.Sprintf("PUBCOMP %d", packetID)
+	}
+	if err != nil {
+		return "", err
+	}
+	// broker may close connection before reading DISCONNECT, message is delivered anyway
+	_, _ = conn.Write([]byte{mqttPacketDisconnect, 0x00})
+	return ack, nil
+}
+
+// discardSession connects with clean session, which makes broker drop persistent session of clientID
+func (m mqttPushProvider) discardSession(ctx context.Context, clientID string) error {
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	_, err = m.connect(conn, bufio.NewReader(conn), clientID, true)
+	if err != nil {
+		return err
+	}
+	_, _ = conn.Write([]byte{mqttPacketDisconnect, 0x00})
+	return nil
+}
+
+// dial connects to broker, deadline of the whole session is set on returned conn
+func (m mqttPushProvider) dial(ctx context.Context) (net.Conn, error) {
+	serverURL, err := url.Parse(m.ProviderServerURL)
+	if err != nil {
+		return nil, err
+	}
+	port := serverURL.Port()
+	if port == "" {
+		port = "1883"
+		if serverURL.Scheme == "mqtts" {
+			port = "8883"
+		}
+	}
+	addr := net.JoinHostPort(serverURL.Hostname(), port)
+	dialer := &net.Dialer{Timeout: m.HTTPClient.timeoutOrDefault()}
+	var conn net.Conn
+	if serverURL.Scheme == "mqtts" {
+		tlsConf, err := NewTLSConfig(m.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.ServerName = serverURL.Hostname()
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConf}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_ = conn.SetDeadline(time.Now().Add(m.HTTPClient.timeoutOrDefault()))
+	return conn, nil
+}
+
+// connect sends CONNECT and checks return code of CONNACK, it returns session present flag
+func (m mqttPushProvider) connect(conn net.Conn, r *bufio.Reader, clientID string, cleanSession bool) (bool, error) {
+	_, err := conn.Write(m.connectPacket(clientID, cleanSession))
+	if err != nil {
+		return false, err
+	}
+	pType, body, err := readMQTTPacket(r)
+	if err != nil {
+		return false, err
+	}
+	if pType != mqttPacketConnAck || len(body) != 2 {
+		return false, fmt.Errorf("%w: expect CONNACK, got packet 0x%02X", ErrMQTTProtocol, pType)
+	}
+	if rc := body[1]; rc != 0 {
+		kind := ErrHttpRequestFailed
+		switch rc {
+		case 3:
+			kind = ErrServerError
+		case 4, 5:
+			kind = ErrAuthFailed
+		}
+		return false, &ProviderError{Provider: MQTT, Kind: kind, Code: int(rc), Message: mqttConnAckErrors[rc]}
+	}
+	return body[0]&0x01 == 1, nil
+}
+
+func (m mqttPushProvider) connectPacket(clientID string, cleanSession bool) []byte {
+	var username, password string
+	if m.ExtraParams != nil {
+		username = m.ExtraParams.Username
+		password = m.ExtraParams.Password
+	}
+	var flags byte
+	if cleanSession {
+		flags |= 0x02
+	}
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	body := appendMQTTString(nil, "MQTT")
+	body = append(body, 0x04, flags)
+	body = binary.BigEndian.AppendUint16(body, mqttKeepAlive)
+	body = appendMQTTString(body, clientID)
+	if username != "" {
+		body = appendMQTTString(body, username)
+		if password != "" {
+			body = appendMQTTString(body, password)
+		}
+	}
+	return encodeMQTTPacket(mqttPacketConnect, body)
+}
+
+// publishPacket encodes PUBLISH, dup is set when it's re-sent in a resumed session
+func publishPacket(pData *mqttPushContent, payload []byte, packetID uint16, dup bool) []byte {
+	header := byte(mqttPacketPublish) | pData.QoS<<1
+	if pData.Retain {
+		header |= 0x01
+	}
+	if dup {
+		header |= mqttPublishDup
+	}
+	varHeader := appendMQTTString(nil, pData.Topic)
+	if pData.QoS > 0 {
+		varHeader = binary.BigEndian.AppendUint16(varHeader, packetID)
+	}
+	return encodeMQTTPacket(header, append(varHeader, payload...))
+}
+
+// encodeMQTTPacket prepends fixed header, remaining length is a variable byte integer
+func encodeMQTTPacket(header byte, body []byte) []byte {
+	pkt := make([]byte, 0, len(body)+5)
+	pkt = append(pkt, header)
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		pkt = append(pkt, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(pkt, body...)
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readMQTTPacket reads a whole control packet, flags of fixed header are kept in packet type
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	pType, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("%w: malformed remaining length", ErrMQTTProtocol)
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return pType, body, nil
+}
+
+// expectMQTTAck waits for acknowledgement of packetID, PUBREL flags are ignored when comparing type
+func expectMQTTAck(r *bufio.Reader, pType byte, packetID uint16) error {
+	got, body, err := readMQTTPacket(r)
+	if err != nil {
+		return err
+	}
+	if got&0xF0 != pType&0xF0 || len(body) != 2 || binary.BigEndian.Uint16(body) != packetID {
+		return fmt.Errorf("%w: expect packet 0x%02X for ID %d, got packet 0x%02X", ErrMQTTProtocol, pType, packetID, got)
+	}
+	return nil
+}
+
+type mqttPushProviderExtraParams struct {
+	// Topic is a template, rdpalert/{hostname}/login by default
+	Topic string `json:"topic,omitempty" validate:"omitempty,max=65535,excludesall=+#"`
+	QoS   int    `json:"qos,omitempty" validate:"omitempty,gte=0,lte=2"`
+	// Retain keeps the last alert on broker for new subscribers, e.g. dashboards
+	Retain bool `json:"retain,omitempty"`
+	// ClientID is random if empty, it must be unique on broker
+	ClientID string `json:"clientID,omitempty" validate:"omitempty,max=65535"`
+	Username string `json:"username,omitempty" validate:"omitempty"`
+	Password string `json:"password,omitempty" validate:"excluded_without=Username"`
+}
@@ -0,0 +1,383 @@
+package pushsdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMQTTPacket is a control packet received by fakeMQTTBroker, conn counts connections from 1
+type fakeMQTTPacket struct {
+	conn   int
+	header byte
+	body   []byte
+}
+
+// fakeMQTTConnect is the decoded CONNECT packet
+type fakeMQTTConnect struct {
+	flags    byte
+	clientID string
+	username string
+	password string
+}
+
+// fakeMQTTBroker is a MQTT 3.1.1 broker just enough for a publisher, it keeps QoS 2 state of
+// persistent sessions across connections and counts messages delivered to subscribers
+type fakeMQTTBroker struct {
+	addr string
+	// returnCode is replied in CONNACK
+	returnCode byte
+	// dropOn is a packet type, the first connection is closed instead of replying to it
+	dropOn byte
+
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	conns     int
+	packets   []fakeMQTTPacket
+	connects  []fakeMQTTConnect
+	delivered []string
+	// sessions holds packet IDs received but not released yet, keyed by client ID
+	sessions map[string]map[uint16]bool
+}
+
+func newFakeMQTTBroker(t *testing.T) *fakeMQTTBroker {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	b := &fakeMQTTBroker{addr: l.Addr().String(), sessions: map[string]map[uint16]bool{}}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			b.wg.Add(1)
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeMQTTBroker) serve(conn net.Conn) {
+	defer b.wg.Done()
+	defer func() { _ = conn.Close() }()
+	b.mu.Lock()
+	b.conns++
+	connNo := b.conns
+	b.mu.Unlock()
+	r := bufio.NewReader(conn)
+	clientID := ""
+	for {
+		header, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.packets = append(b.packets, fakeMQTTPacket{conn: connNo, header: header, body: body})
+		var reply []byte
+		switch header & 0xF0 {
+		case mqttPacketConnect:
+			c := parseFakeMQTTConnect(body)
+			b.connects = append(b.connects, c)
+			clientID = c.clientID
+			_, present := b.sessions[clientID]
+			if c.flags&0x02 != 0 {
+				delete(b.sessions, clientID)
+				present = false
+			} else if !present {
+				b.sessions[clientID] = map[uint16]bool{}
+			}
+			reply = []byte{mqttPacketConnAck, 2, 0, b.returnCode}
+			if present && b.returnCode == 0 {
+				reply[2] = 1
+			}
+		case mqttPacketPublish:
+			qos := (header >> 1) & 0x03
+			topicLen := int(binary.BigEndian.Uint16(body))
+			payload := body[2+topicLen:]
+			var packetID uint16
+			if qos > 0 {
+				packetID = binary.BigEndian.Uint16(payload)
+				payload = payload[2:]
+			}
+			switch qos {
+			case 0:
+				b.delivered = append(b.delivered, string(payload))
+			case 1:
+				b.delivered = append(b.delivered, string(payload))
+				reply = binary.BigEndian.AppendUint16([]byte{mqttPacketPubAck, 2}, packetID)
+			case 2:
+				// a packet ID waiting for PUBREL is a duplicate, it's only acknowledged
+				if session := b.sessions[clientID]; session == nil || !session[packetID] {
+					b.delivered = append(b.delivered, string(payload))
+					if session != nil {
+						session[packetID] = true
+					}
+				}
+				reply = binary.BigEndian.AppendUint16([]byte{mqttPacketPubRec, 2}, packetID)
+			}
+		case mqttPacketPubRel & 0xF0:
+			packetID := binary.BigEndian.Uint16(body)
+			delete(b.sessions[clientID], packetID)
+			reply = binary.BigEndian.AppendUint16([]byte{mqttPacketPubComp, 2}, packetID)
+		case mqttPacketDisconnect:
+			b.mu.Unlock()
+			return
+		}
+		drop := connNo == 1 && b.dropOn != 0 && header&0xF0 == b.dropOn&0xF0
+		b.mu.Unlock()
+		if drop {
+			return
+		}
+		if reply != nil {
+			_, _ = conn.Write(reply)
+		}
+		if header&0xF0 == mqttPacketConnect && b.returnCode != 0 {
+			return
+		}
+	}
+}
+
+func parseFakeMQTTConnect(body []byte) fakeMQTTConnect {
+	readString := func() string {
+		n := int(binary.BigEndian.Uint16(body))
+		s := string(body[2 : 2+n])
+		body = body[2+n:]
+		return s
+	}
+	_ = readString() // protocol name
+	c := fakeMQTTConnect{flags: body[1]}
+	body = body[4:] // level, flags and keep alive
+	c.clientID = readString()
+	if c.flags&0x80 != 0 {
+		c.username = readString()
+	}
+	if c.flags&0x40 != 0 {
+		c.password = readString()
+	}
+	return c
+}
+
+// wait returns once every connection is closed by client, state of broker is safe to read then
+func (b *fakeMQTTBroker) wait() {
+	b.wg.Wait()
+}
+
+// packetsOf returns packets of type in order, flags are ignored when comparing type
+func (b *fakeMQTTBroker) packetsOf(pType byte) []fakeMQTTPacket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var res []fakeMQTTPacket
+	for _, v := range b.packets {
+		if v.header&0xF0 == pType&0xF0 {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func sendMQTT(b *fakeMQTTBroker, common ProviderCommon, params *mqttPushProviderExtraParams) (*PushResponse, error) {
+	prv := &mqttPushProvider{ProviderCommon: common, ProviderServerURL: "mqtt://" + b.addr, ExtraParams: params}
+	if err := prv.VerifyConfig(); err != nil {
+		return nil, err
+	}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+		Title:     "RDP Login - Success",
+		EventType: EventRDPLoginSuccess,
+		Fields:    []PushField{{Name: FieldHost, Value: "WIN/SRV01"}, {Name: FieldUser, Value: "alice"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := prv.SendPushContent(context.Background(), spc)
+	b.wait()
+	return resp, err
+}
+
+// retryOnce allows a second attempt right away
+func retryOnce() ProviderCommon {
+	return ProviderCommon{Retry: &RetryPolicy{
+		MaxAttempts:        2,
+		BaseDelay:          ptrTo(Duration(time.Millisecond)),
+		RetryableNetErrors: []string{NetErrAll},
+	}}
+}
+
+func TestMQTTPublishQoS0(t *testing.T) {
+	b := newFakeMQTTBroker(t)
+	resp, err := sendMQTT(b, noRetry(), &mqttPushProviderExtraParams{
+		Topic:    "alerts/{hostname}/{user}",
+		Retain:   true,
+		Username: "rdp",
+		Password: "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "" {
+		t.Errorf("ack = %q, want empty for QoS 0", resp.Ack)
+	}
+	c := b.connects[0]
+	// user name, password and clean session
+	if c.flags != 0xC2 || c.username != "rdp" || c.password != "s3cret" {
+		t.Errorf("connect = %+v, want flags 0xC2 with credentials", c)
+	}
+	if !strings.HasPrefix(c.clientID, "rdpalert-") {
+		t.Errorf("client ID = %q, want a random one", c.clientID)
+	}
+	pubs := b.packetsOf(mqttPacketPublish)
+	if len(pubs) != 1 || pubs[0].header != 0x31 {
+		t.Fatalf("publish packets = %v, want one with retain and QoS 0", pubs)
+	}
+	topic := "alerts/WIN_SRV01/alice"
+	body := pubs[0].body
+	if got := string(body[2 : 2+len(topic)]); got != topic {
+		t.Errorf("topic = %q, want %q", got, topic)
+	}
+	ev := &alertEvent{}
+	if err := json.Unmarshal(body[2+len(topic):], ev); err != nil {
+		t.Errorf("payload without packet ID is not an event: %v", err)
+	}
+	if len(b.packetsOf(mqttPacketDisconnect)) != 1 {
+		t.Errorf("session is not ended with DISCONNECT")
+	}
+}
+
+func TestMQTTPublishQoS1(t *testing.T) {
+	b := newFakeMQTTBroker(t)
+	resp, err := sendMQTT(b, noRetry(), &mqttPushProviderExtraParams{QoS: 1, ClientID: "rdpalert-srv01", Username: "rdp"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "PUBACK 1" {
+		t.Errorf("ack = %q", resp.Ack)
+	}
+	c := b.connects[0]
+	if c.flags != 0x82 || c.clientID != "rdpalert-srv01" || c.password != "" {
+		t.Errorf("connect = %+v, want flags 0x82 without password", c)
+	}
+	pubs := b.packetsOf(mqttPacketPublish)
+	if len(pubs) != 1 || pubs[0].header != 0x32 {
+		t.Fatalf("publish packets = %v, want one with QoS 1", pubs)
+	}
+	topic := "rdpalert/WIN_SRV01/login"
+	if id := binary.BigEndian.Uint16(pubs[0].body[2+len(topic):]); id != 1 {
+		t.Errorf("packet ID = %d, want 1", id)
+	}
+}
+
+func TestMQTTPublishQoS2(t *testing.T) {
+	b := newFakeMQTTBroker(t)
+	resp, err := sendMQTT(b, noRetry(), &mqttPushProviderExtraParams{QoS: 2})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "PUBCOMP 1" {
+		t.Errorf("ack = %q", resp.Ack)
+	}
+	pubs := b.packetsOf(mqttPacketPublish)
+	if len(pubs) != 1 || pubs[0].header != 0x34 {
+		t.Fatalf("publish packets = %v, want one with QoS 2", pubs)
+	}
+	rels := b.packetsOf(mqttPacketPubRel)
+	if len(rels) != 1 || rels[0].header != mqttPacketPubRel || binary.BigEndian.Uint16(rels[0].body) != 1 {
+		t.Errorf("pubrel packets = %v, want one for packet ID 1", rels)
+	}
+	// persistent session for the flow, then a clean one to discard it
+	if len(b.connects) != 2 || b.connects[0].flags != 0x00 || b.connects[1].flags != 0x02 ||
+		b.connects[0].clientID != b.connects[1].clientID {
+		t.Errorf("connects = %+v, want persistent session discarded after push", b.connects)
+	}
+	if len(b.sessions) != 0 {
+		t.Errorf("sessions left on broker: %v", b.sessions)
+	}
+}
+
+func TestMQTTQoS2Resume(t *testing.T) {
+	cases := []struct {
+		name   string
+		dropOn byte
+		// pubs are headers of PUBLISH received
+		pubs []byte
+	}{
+		// broker got PUBLISH but PUBREC is lost, PUBLISH is re-sent as duplicate
+		{"lost PUBREC", mqttPacketPublish, []byte{0x34, 0x3C}},
+		// broker got PUBREL but PUBCOMP is lost, only PUBREL is re-sent
+		{"lost PUBCOMP", mqttPacketPubRel, []byte{0x34}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := newFakeMQTTBroker(t)
+			b.dropOn = c.dropOn
+			_, err := sendMQTT(b, retryOnce(), &mqttPushProviderExtraParams{QoS: 2})
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			if len(b.delivered) != 1 {
+				t.Errorf("message delivered %d times, want exactly once", len(b.delivered))
+			}
+			var headers []byte
+			for _, v := range b.packetsOf(mqttPacketPublish) {
+				headers = append(headers, v.header)
+			}
+			if string(headers) != string(c.pubs) {
+				t.Errorf("publish headers = % X, want % X", headers, c.pubs)
+			}
+			if len(b.connects) < 2 || b.connects[1].clientID != b.connects[0].clientID || b.connects[1].flags&0x02 != 0 {
+				t.Errorf("connects = %+v, want retry to resume the session", b.connects)
+			}
+		})
+	}
+}
+
+func TestMQTTQoS2StaleSession(t *testing.T) {
+	// an earlier push of the same client ID gave up before PUBREL
+	b := newFakeMQTTBroker(t)
+	b.sessions["rdpalert-srv01"] = map[uint16]bool{1: true}
+	_, err := sendMQTT(b, noRetry(), &mqttPushProviderExtraParams{QoS: 2, ClientID: "rdpalert-srv01"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(b.delivered) != 1 {
+		t.Errorf("message delivered %d times, want once", len(b.delivered))
+	}
+}
+
+func TestMQTTConnAckError(t *testing.T) {
+	cases := []struct {
+		rc   byte
+		kind error
+	}{
+		{1, ErrHttpRequestFailed},
+		{2, ErrHttpRequestFailed},
+		{3, ErrServerError},
+		{4, ErrAuthFailed},
+		{5, ErrAuthFailed},
+	}
+	for _, c := range cases {
+		b := newFakeMQTTBroker(t)
+		b.returnCode = c.rc
+		_, err := sendMQTT(b, noRetry(), &mqttPushProviderExtraParams{Username: "rdp", Password: "wrong"})
+		var pErr *ProviderError
+		if !errors.As(err, &pErr) {
+			t.Errorf("rc %d: error = %v, want *ProviderError", c.rc, err)
+			continue
+		}
+		if pErr.Kind != c.kind || pErr.Code != int(c.rc) || pErr.Message != mqttConnAckErrors[c.rc] {
+			t.Errorf("rc %d: error = %+v, want kind %v", c.rc, pErr, c.kind)
+		}
+		if len(b.packetsOf(mqttPacketPublish)) != 0 {
+			t.Errorf("rc %d: PUBLISH is sent after refused connection", c.rc)
+		}
+	}
+}
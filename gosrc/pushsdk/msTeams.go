@@ -0,0 +1,214 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion     = "1.4"
+)
+
+var (
+	// adaptiveCardMarkdownEscaper escapes emphasis and link syntax supported by TextBlock and Fact value,
+	// check: https://learn.microsoft.com/en-us/adaptive-cards/authoring-cards/text-features
+	adaptiveCardMarkdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	)
+	// adaptiveCardListMarkerRegex matches bullet or numbered list marker at the start of a line
+	adaptiveCardListMarkerRegex = regexp.MustCompile(`(?m)^(\s*\d*)([-+.])(\s)`)
+)
+
+// escapeAdaptiveCardMarkdown makes s a literal text in TextBlock or Fact value,
+// as login info may be attacker controlled
+func escapeAdaptiveCardMarkdown(s string) string {
+	return adaptiveCardListMarkerRegex.ReplaceAllString(adaptiveCardMarkdownEscaper.Replace(s), `$1\$2$3`)
+}
+
+// adaptiveCardTitleColors maps Severity to TextBlock color of adaptive card
+var adaptiveCardTitleColors = map[Severity]string{
+	SeverityInfo:     "Accent",
+	SeverityWarning:  "Warning",
+	SeverityError:    "Attention",
+	SeverityCritical: "Attention",
+}
+
+// teamsPushContent is a message with single adaptive card attachment,
+// check: https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using
+type teamsPushContent struct {
+	Type        string                `json:"type"`
+	Attachments []teamsCardAttachment `json:"attachments" validate:"required,min=1"`
+
+	actionTitle string
+	actionURL   string
+	// provider info
+	providerName PushProvider
+}
+
+type teamsCardAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                `json:"$schema"`
+	Type    string                `json:"type"`
+	Version string                `json:"version"`
+	Body    []adaptiveCardElement `json:"body"`
+	Actions []adaptiveCardAction  `json:"actions,omitempty"`
+	MSTeams map[string]string     `json:"msteams,omitempty"`
+}
+
+// adaptiveCardElement covers TextBlock and FactSet
+type adaptiveCardElement struct {
+	Type     string             `json:"type"`
+	Text     string             `json:"text,omitempty"`
+	Weight   string             `json:"weight,omitempty"`
+	Size     string             `json:"size,omitempty"`
+	Color    string             `json:"color,omitempty"`
+	IsSubtle bool               `json:"isSubtle,omitempty"`
+	Wrap     bool               `json:"wrap,omitempty"`
+	Facts    []adaptiveCardFact `json:"facts,omitempty"`
+}
+
+type adaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type adaptiveCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func (tpc *teamsPushContent) Init() {
+	tpc.Type = "message"
+	tpc.SetPushProvider()
+}
+
+func (tpc *teamsPushContent) Provider() PushProvider {
+	return tpc.providerName
+}
+
+func (tpc *teamsPushContent) SetPushProvider() {
+	tpc.providerName = MSTeams
+}
+
+func (tpc *teamsPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*cardPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	tpc.actionTitle = d1.ActionTitle
+	tpc.actionURL = d1.ActionURL
+}
+
+// FromGeneral renders title, short title and login fields as facts, text is escaped as TextBlock renders markdown
+func (tpc *teamsPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	card := adaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		Body: []adaptiveCardElement{
+			{Type: "TextBlock", Text: escapeAdaptiveCardMarkdown(g.Title), Weight: "Bolder", Size: "Large", Color: adaptiveCardTitleColors[g.SeverityOrDefault()], Wrap: true},
+			{Type: "TextBlock", Text: escapeAdaptiveCardMarkdown(g.ShortTitle), IsSubtle: true, Wrap: true},
+		},
+		MSTeams: map[string]string{"width": "Full"},
+	}
+	if len(g.Fields) == 0 {
+		card.Body = append(card.Body, adaptiveCardElement{Type: "TextBlock", Text: escapeAdaptiveCardMarkdown(g.Description), Wrap: true})
+	} else {
+		facts := adaptiveCardElement{Type: "FactSet"}
+		for _, v := range g.Fields {
+			facts.Facts = append(facts.Facts, adaptiveCardFact{Title: v.Name, Value: escapeAdaptiveCardMarkdown(v.Value)})
+		}
+		card.Body = append(card.Body, facts)
+	}
+	if tpc.actionURL != "" {
+		card.Actions = []adaptiveCardAction{{Type: "Action.OpenUrl", Title: tpc.actionTitle, URL: tpc.actionURL}}
+	}
+	tpc.Attachments = []teamsCardAttachment{{ContentType: adaptiveCardContentType, Content: card}}
+	return tpc, nil
+}
+
+func (tpc *teamsPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(tpc)
+}
+
+func init() {
+	MustRegisterProvider(MSTeams, func() PushProviderImpl { return &teamsPushProvider{} })
+}
+
+type teamsPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is HTTP trigger URL of "Post to a channel when a webhook request is received" workflow
+	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
+	ExtraParams       *cardPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (t teamsPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(t)
+	if err1 != nil {
+		return err1
+	}
+	if t.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(t.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (t teamsPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	tpc := &teamsPushContent{}
+	tpc.Init()
+	tpc.AcceptExtParamSettings(t.ExtraParams)
+	return tpc.FromGeneral(g)
+}
+
+// SendPushContent posts card to workflow, which answers 202 with empty body
+func (t teamsPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*teamsPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if u, err := url.Parse(t.ProviderServerURL); err == nil && u.RawQuery != "" {
+		secrets = append(secrets, u.RawQuery)
+	}
+	respData, statusCode, err := t.SendRequest(ctx, MSTeams, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     t.ProviderServerURL,
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   truncateString(string(respData), 256),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// cardPushProviderExtraParams is shared by card based providers, teams and gchat
+type cardPushProviderExtraParams struct {
+	// ActionURL adds a button opening it, e.g. jump host portal
+	ActionURL   string `json:"actionURL,omitempty" validate:"omitempty,url"`
+	ActionTitle string `json:"actionTitle,omitempty" validate:"required_with=ActionURL"`
+	// ThreadKey groups messages into one thread, gchat only
+	ThreadKey string `json:"threadKey,omitempty" validate:"omitempty"`
+}
@@ -0,0 +1,40 @@
+package pushsdk
+
+import "testing"
+
+func TestEscapeAdaptiveCardMarkdown(t *testing.T) {
+	cases := []struct {
+		s    string
+		want string
+	}{
+		{"RDP Login - Success", "RDP Login - Success"},
+		{"**admin**", `\*\*admin\*\*`},
+		{"[click](https://evil.example)", `\[click\]\(https://evil.example\)`},
+		{"CORP\\alice_01", `CORP\\alice\_01`},
+		{"- item\n1. first", "\\- item\n1\\. first"},
+	}
+	for _, c := range cases {
+		if got := escapeAdaptiveCardMarkdown(c.s); got != c.want {
+			t.Errorf("escapeAdaptiveCardMarkdown(%q) = %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestTeamsCardEscapesLoginInfo(t *testing.T) {
+	prv := teamsPushProvider{}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+		Title:      "RDP Login - Success",
+		ShortTitle: "**boss** from 10.0.0.8",
+		Fields:     []PushField{{Name: FieldUser, Value: "[admin](https://evil.example)"}},
+	})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	body := spc.(*teamsPushContent).Attachments[0].Content.Body
+	if want := `\*\*boss\*\* from 10.0.0.8`; body[1].Text != want {
+		t.Errorf("short title = %q, want %q", body[1].Text, want)
+	}
+	if want := `\[admin\]\(https://evil.example\)`; body[2].Facts[0].Value != want {
+		t.Errorf("fact value = %q, want %q", body[2].Facts[0].Value, want)
+	}
+}
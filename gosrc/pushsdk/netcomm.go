@@ -2,10 +2,17 @@ package pushsdk
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"rdpalert/embedded"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -17,20 +24,100 @@ var (
 	customUserAgent      = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/134.0.0.0 Safari/537.36 Patrick-RDPAlert-Assist/" + embedded.CurVersionStr
 )
 
+// HTTPStatusError is returned when server answers with unexpected status code
+type HTTPStatusError struct {
+	StatusCode int
+	Body       []byte
+	// RetryAfter is parsed from Retry-After header, 0 if absent
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: status %d, body: %s", ErrHttpRequestFailed.Error(), e.StatusCode, string(e.Body))
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	return ErrHttpRequestFailed
+}
+
+// parseRetryAfter accepts both delay-seconds and HTTP-date form
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// basicAuthHeader returns value of Authorization header for HTTP basic auth
+func basicAuthHeader(username string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
 type customUserAgentRT struct {
-	UserAgent string `json:"userAgent"`
+	UserAgent string            `json:"userAgent"`
+	Base      http.RoundTripper `json:"-"`
 }
 
 func (cuart *customUserAgentRT) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set("User-Agent", cuart.UserAgent)
-	return http.DefaultTransport.RoundTrip(req)
+	if cuart.Base == nil {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	return cuart.Base.RoundTrip(req)
+}
+
+// HTTPRequest describes a single request sent by SendHttpRequest
+type HTTPRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+	// AcceptStatus tells whether status code means success, nil accepts any 2xx
+	AcceptStatus func(code int) bool
+	// RetryAfter extracts retry delay from a failed response, nil reads Retry-After header only
+	RetryAfter func(header http.Header, body []byte) time.Duration
+	// Secrets are masked in returned errors, e.g. token embedded in URL
+	Secrets []string
 }
 
-func SendHttpPostJSON(url string, body []byte) ([]byte, int, error) {
-	http.DefaultClient.Transport = &customUserAgentRT{UserAgent: customUserAgent}
-	buf := bytes.NewBuffer(body)
-	respD, err := http.Post(url, postJSONContentType, buf)
+// SendHttpRequest sends r with client, nil client means a default one built by NewHTTPClient,
+// *HTTPStatusError is returned with response body if status code is not accepted
+func SendHttpRequest(ctx context.Context, client *http.Client, r *HTTPRequest) ([]byte, int, error) {
+	if client == nil {
+		var err error
+		client, err = NewHTTPClient(nil)
+		if err != nil {
+			return nil, -1, err
+		}
+	}
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, body)
+	if err != nil {
+		return nil, -1, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	respD, err := client.Do(req)
 	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			for _, v := range r.Secrets {
+				if v == "" {
+					continue
+				}
+				urlErr.URL = strings.ReplaceAll(urlErr.URL, v, "***")
+			}
+		}
 		return nil, -1, err
 	}
 	resp, err := io.ReadAll(respD.Body)
@@ -38,8 +125,31 @@ func SendHttpPostJSON(url string, body []byte) ([]byte, int, error) {
 	if err != nil {
 		return nil, respD.StatusCode, err
 	}
-	if respD.StatusCode != http.StatusOK {
-		return resp, respD.StatusCode, ErrHttpRequestFailed
+	accepted := respD.StatusCode >= 200 && respD.StatusCode < 300
+	if r.AcceptStatus != nil {
+		accepted = r.AcceptStatus(respD.StatusCode)
+	}
+	if !accepted {
+		retryAfter := parseRetryAfter(respD.Header.Get("Retry-After"))
+		if r.RetryAfter != nil {
+			retryAfter = r.RetryAfter(respD.Header, resp)
+		}
+		return resp, respD.StatusCode, &HTTPStatusError{
+			StatusCode: respD.StatusCode,
+			Body:       resp,
+			RetryAfter: retryAfter,
+		}
 	}
 	return resp, respD.StatusCode, nil
 }
+
+// SendHttpPostJSON posts body with client, nil client means a default one built by NewHTTPClient
+func SendHttpPostJSON(ctx context.Context, client *http.Client, url string, body []byte) ([]byte, int, error) {
+	return SendHttpRequest(ctx, client, &HTTPRequest{
+		Method:       http.MethodPost,
+		URL:          url,
+		Header:       http.Header{"Content-Type": {postJSONContentType}},
+		Body:         body,
+		AcceptStatus: func(code int) bool { return code == http.StatusOK },
+	})
+}
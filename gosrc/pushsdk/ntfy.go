@@ -0,0 +1,247 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultNtfyPriority maps Severity to ntfy priority, 1 is min and 5 is urgent
+var defaultNtfyPriority = map[Severity]int{
+	SeverityInfo:     3,
+	SeverityWarning:  4,
+	SeverityError:    5,
+	SeverityCritical: 5,
+}
+
+// ntfyPushContent is published in header way, check: https://docs.ntfy.sh/publish/
+// Message is sent as request body, everything else goes to X- headers
+type ntfyPushContent struct {
+	Title    string
+	Message  string
+	Priority int
+	Tags     []string
+	Click    string
+	Actions  []ntfyAction
+	Markdown bool
+
+	priorityMap map[Severity]int
+	// provider info
+	providerName PushProvider
+}
+
+func (npc *ntfyPushContent) Init() {
+	npc.priorityMap = defaultNtfyPriority
+	npc.SetPushProvider()
+}
+
+func (npc *ntfyPushContent) Provider() PushProvider {
+	return npc.providerName
+}
+
+func (npc *ntfyPushContent) SetPushProvider() {
+	npc.providerName = Ntfy
+}
+
+func (npc *ntfyPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*ntfyPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if len(d1.PriorityMap) != 0 {
+		npc.priorityMap = make(map[Severity]int, len(defaultNtfyPriority))
+		for k, v := range defaultNtfyPriority {
+			npc.priorityMap[k] = v
+		}
+		for k, v := range d1.PriorityMap {
+			npc.priorityMap[k] = v
+		}
+	}
+	npc.Tags = append(npc.Tags, d1.Tags...)
+	npc.Click = d1.Click
+	npc.Actions = d1.Actions
+	npc.Markdown = d1.Markdown
+}
+
+func (npc *ntfyPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	npc.Title = g.Title
+	npc.Message = g.Description
+	npc.Priority = npc.priorityMap[g.SeverityOrDefault()]
+	npc.Tags = append(npc.Tags, g.TagsOrGroups...)
+	return npc, nil
+}
+
+func (npc *ntfyPushContent) ToBytes() ([]byte, error) {
+	return []byte(npc.Message), nil
+}
+
+// headers builds ntfy X- headers, non-ASCII values are RFC 2047 encoded as ntfy accepts
+func (npc *ntfyPushContent) headers() (http.Header, error) {
+	h := http.Header{}
+	h.Set("Content-Type", "text/plain; charset=utf-8")
+	if npc.Title != "" {
+		h.Set("X-Title", mime.BEncoding.Encode("UTF-8", npc.Title))
+	}
+	if npc.Priority != 0 {
+		h.Set("X-Priority", strconv.Itoa(npc.Priority))
+	}
+	if len(npc.Tags) != 0 {
+		h.Set("X-Tags", mime.BEncoding.Encode("UTF-8", strings.Join(npc.Tags, ",")))
+	}
+	if npc.Click != "" {
+		h.Set("X-Click", npc.Click)
+	}
+	if len(npc.Actions) != 0 {
+		actions, err := json.Marshal(npc.Actions)
+		if err != nil {
+			return nil, err
+		}
+		h.Set("X-Actions", mime.BEncoding.Encode("UTF-8", string(actions)))
+	}
+	if npc.Markdown {
+		h.Set("X-Markdown", "yes")
+	}
+	return h, nil
+}
+
+func init() {
+	MustRegisterProvider(Ntfy, func() PushProviderImpl { return &ntfyPushProvider{} })
+}
+
+type ntfyPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is the topic URL, e.g. https://ntfy.sh/mytopic
+	ProviderServerURL string                       `json:"serverURL" validate:"url,required"`
+	ExtraParams       *ntfyPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (n ntfyPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(n)
+	if err1 != nil {
+		return err1
+	}
+	if n.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(n.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (n ntfyPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	npc := &ntfyPushContent{}
+	npc.Init()
+	npc.AcceptExtParamSettings(n.ExtraParams)
+	return npc.FromGeneral(g)
+}
+
+func (n ntfyPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*ntfyPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	header, err := pData.headers()
+	if err != nil {
+		return nil, err
+	}
+	if n.ExtraParams != nil {
+		switch {
+		case n.ExtraParams.AccessToken != "":
+			header.Set("Authorization", "Bearer "+n.ExtraParams.AccessToken)
+		case n.ExtraParams.Username != "":
+			header.Set("Authorization", basicAuthHeader(n.ExtraParams.Username, n.ExtraParams.Password))
+		}
+	}
+	respData, statusCode, err := n.SendRequest(ctx, Ntfy, &HTTPRequest{
+		Method: http.MethodPost,
+		URL:    n.ProviderServerURL,
+		Header: header,
+		Body:   body,
+	})
+	if err != nil {
+		return nil, parseNtfyError(err)
+	}
+	npr := &ntfyPushResponse{}
+	err = json.Unmarshal(respData, npr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("ID: %s, Topic: %s, Expires: %d", npr.ID, npr.Topic, npr.Expires),
+		Timestamp: npr.Time,
+	}, nil
+}
+
+type ntfyPushResponse struct {
+	ID      string `json:"id"`
+	Time    int64  `json:"time"`
+	Expires int64  `json:"expires"`
+	Event   string `json:"event"`
+	Topic   string `json:"topic"`
+}
+
+// ntfyErrorResponse is replied with status other than 200, code is HTTP status followed by 2 digits, e.g. 40301
+type ntfyErrorResponse struct {
+	Code  int    `json:"code"`
+	HTTP  int    `json:"http"`
+	Error string `json:"error"`
+	Link  string `json:"link"`
+}
+
+// parseNtfyError turns error response into *ProviderError, kind is still decided by HTTP status
+func parseNtfyError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	ner := &ntfyErrorResponse{}
+	code, msg := statusErr.StatusCode, truncateString(strings.TrimSpace(string(statusErr.Body)), 200)
+	if json.Unmarshal(statusErr.Body, ner) == nil && ner.Code != 0 {
+		code, msg = ner.Code, ner.Error
+		if ner.Link != "" {
+			msg += ", see " + ner.Link
+		}
+	}
+	return &ProviderError{
+		Provider: Ntfy,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     code,
+		Message:  msg,
+		Err:      err,
+	}
+}
+
+type ntfyPushProviderExtraParams struct {
+	// AccessToken is sent as Bearer token, Username and Password are used for basic auth instead
+	AccessToken string `json:"accessToken,omitempty" validate:"omitempty,excluded_with=Username"`
+	Username    string `json:"username,omitempty" validate:"omitempty"`
+	Password    string `json:"password,omitempty" validate:"required_with=Username"`
+	// PriorityMap overrides defaultNtfyPriority
+	PriorityMap map[Severity]int `json:"priorityMap,omitempty" validate:"omitempty,dive,keys,oneof=info warning error critical,endkeys,gte=1,lte=5"`
+	// Tags are added besides GeneralPushContent.TagsOrGroups, could be emoji short codes
+	Tags     []string     `json:"tags,omitempty" validate:"omitempty"`
+	Click    string       `json:"click,omitempty" validate:"omitempty,url"`
+	Actions  []ntfyAction `json:"actions,omitempty" validate:"omitempty,max=3,dive"`
+	Markdown bool         `json:"markdown,omitempty"`
+}
+
+// ntfyAction is an action button, check: https://docs.ntfy.sh/publish/#action-buttons
+type ntfyAction struct {
+	Action  string            `json:"action" validate:"required,oneof=view http broadcast"`
+	Label   string            `json:"label" validate:"required"`
+	URL     string            `json:"url,omitempty" validate:"required_unless=Action broadcast,omitempty,url"`
+	Method  string            `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT DELETE"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Extras  map[string]string `json:"extras,omitempty"`
+	Clear   bool              `json:"clear,omitempty"`
+}
@@ -0,0 +1,148 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ntfyCapture is what the fake ntfy server received
+type ntfyCapture struct {
+	header http.Header
+	body   string
+}
+
+func newNtfyServer(t *testing.T, got *ntfyCapture) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got.header = r.Header.Clone()
+		got.body = string(b)
+		_, _ = w.Write([]byte(`{"id":"kZ7V8h0aQv5J","time":1700000000,"expires":1700043200,"event":"message","topic":"alerts"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sendNtfy(t *testing.T, prv *ntfyPushProvider, g *GeneralPushContent) *PushResponse {
+	t.Helper()
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(g)
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	resp, err := prv.SendPushContent(context.Background(), spc)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	return resp
+}
+
+func decodeNtfyHeader(t *testing.T, v string) string {
+	t.Helper()
+	s, err := new(mime.WordDecoder).DecodeHeader(v)
+	if err != nil {
+		t.Fatalf("decode header %q: %v", v, err)
+	}
+	return s
+}
+
+func TestNtfyDefault(t *testing.T) {
+	got := &ntfyCapture{}
+	srv := newNtfyServer(t, got)
+	resp := sendNtfy(t, &ntfyPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/alerts"}, &GeneralPushContent{
+		Title:        "RDP 登录 - Success",
+		Description:  "From: 10.0.0.8\nUser: CORP\\alice",
+		Severity:     SeverityWarning,
+		TagsOrGroups: []string{"rdp", "warning"},
+	})
+	if got.body != "From: 10.0.0.8\nUser: CORP\\alice" {
+		t.Errorf("body = %q", got.body)
+	}
+	if v := decodeNtfyHeader(t, got.header.Get("X-Title")); v != "RDP 登录 - Success" {
+		t.Errorf("X-Title = %q", v)
+	}
+	if v := got.header.Get("X-Priority"); v != "4" {
+		t.Errorf("X-Priority = %q, want 4 for warning", v)
+	}
+	if v := decodeNtfyHeader(t, got.header.Get("X-Tags")); v != "rdp,warning" {
+		t.Errorf("X-Tags = %q", v)
+	}
+	for _, k := range []string{"X-Click", "X-Actions", "X-Markdown", "Authorization"} {
+		if v := got.header.Get(k); v != "" {
+			t.Errorf("%s = %q, want it unset", k, v)
+		}
+	}
+	if resp.Code != http.StatusOK || resp.Timestamp != 1700000000 {
+		t.Errorf("response = %s", resp)
+	}
+}
+
+func TestNtfyWithExtParams(t *testing.T) {
+	g := &GeneralPushContent{
+		Title:        "RDP Login - Success",
+		Description:  "**alice** from 10.0.0.8",
+		Severity:     SeverityCritical,
+		TagsOrGroups: []string{"rdp"},
+	}
+	ext := func() *ntfyPushProviderExtraParams {
+		return &ntfyPushProviderExtraParams{
+			PriorityMap: map[Severity]int{SeverityCritical: 3},
+			Tags:        []string{"rotating_light"},
+			Click:       "https://jump.corp.local/sessions",
+			Actions: []ntfyAction{
+				{Action: "view", Label: "Open", URL: "https://jump.corp.local/"},
+				{Action: "http", Label: "Lock", URL: "https://jump.corp.local/lock", Method: "POST"},
+			},
+			Markdown: true,
+		}
+	}
+	cases := []struct {
+		name     string
+		auth     func(e *ntfyPushProviderExtraParams)
+		wantAuth string
+	}{
+		{"token", func(e *ntfyPushProviderExtraParams) { e.AccessToken = "tk_0123456789" }, "Bearer tk_0123456789"},
+		{"basic", func(e *ntfyPushProviderExtraParams) { e.Username, e.Password = "phil", "mypass" }, "Basic cGhpbDpteXBhc3M="},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := &ntfyCapture{}
+			srv := newNtfyServer(t, got)
+			e := ext()
+			c.auth(e)
+			sendNtfy(t, &ntfyPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/alerts", ExtraParams: e}, g)
+			if v := got.header.Get("Authorization"); v != c.wantAuth {
+				t.Errorf("Authorization = %q, want %q", v, c.wantAuth)
+			}
+			if v := got.header.Get("X-Priority"); v != "3" {
+				t.Errorf("X-Priority = %q, want 3 from priority map", v)
+			}
+			if v := decodeNtfyHeader(t, got.header.Get("X-Tags")); v != "rotating_light,rdp" {
+				t.Errorf("X-Tags = %q", v)
+			}
+			if v := got.header.Get("X-Click"); v != "https://jump.corp.local/sessions" {
+				t.Errorf("X-Click = %q", v)
+			}
+			if v := got.header.Get("X-Markdown"); v != "yes" {
+				t.Errorf("X-Markdown = %q", v)
+			}
+			var actions []ntfyAction
+			if err := json.Unmarshal([]byte(decodeNtfyHeader(t, got.header.Get("X-Actions"))), &actions); err != nil {
+				t.Fatalf("X-Actions: %v", err)
+			}
+			if len(actions) != 2 || actions[0].Action != "view" || actions[1].Method != "POST" {
+				t.Errorf("X-Actions = %+v", actions)
+			}
+			if got.body != g.Description {
+				t.Errorf("body = %q", got.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultOpsgenieAlertsURL = "https://api.opsgenie.com/v2/alerts"
+
+// defaultOpsgeniePriority maps Severity to Opsgenie priority, P1 is the highest
+var defaultOpsgeniePriority = map[Severity]string{
+	SeverityInfo:     "P5",
+	SeverityWarning:  "P3",
+	SeverityError:    "P2",
+	SeverityCritical: "P1",
+}
+
+// opsgeniePushContent is a create alert request, Alias deduplicates open alerts
+type opsgeniePushContent struct {
+	Message     string              `json:"message" validate:"required,max=130"`
+	Alias       string              `json:"alias,omitempty"`
+	Description string              `json:"description,omitempty" validate:"max=15000"`
+	Responders  []opsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source,omitempty"`
+	Priority    string              `json:"priority,omitempty"`
+
+	priorityMap map[Severity]string
+	// provider info
+	providerName PushProvider
+}
+
+// opsgenieResponder is a team, user, escalation or schedule, identified by either ID or name
+type opsgenieResponder struct {
+	Type     string `json:"type" validate:"required,oneof=team user escalation schedule"`
+	ID       string `json:"id,omitempty" validate:"required_without_all=Name Username"`
+	Name     string `json:"name,omitempty" validate:"omitempty"`
+	Username string `json:"username,omitempty" validate:"omitempty"`
+}
+
+func (opc *opsgeniePushContent) Init() {
+	opc.Source = "RDPAlert"
+	opc.priorityMap = defaultOpsgeniePriority
+	opc.SetPushProvider()
+}
+
+func (opc *opsgeniePushContent) Provider() PushProvider {
+	return opc.providerName
+}
+
+func (opc *opsgeniePushContent) SetPushProvider() {
+	opc.providerName = Opsgenie
+}
+
+func (opc *opsgeniePushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*opsgeniePushProviderExtraParams)
+	opc.Responders = d1.Responders
+	opc.Tags = append(opc.Tags, d1.Tags...)
+	if len(d1.PriorityMap) != 0 {
+		opc.priorityMap = make(map[Severity]string, len(defaultOpsgeniePriority))
+		for k, v := range defaultOpsgeniePriority {
+			opc.priorityMap[k] = v
+		}
+		for k, v := range d1.PriorityMap {
+			opc.priorityMap[k] = v
+		}
+	}
+}
+
+// FromGeneral puts structured login fields in details, entity is the host being logged into
+func (opc *opsgeniePushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	opc.Message = truncateString(g.ShortTitle, 130)
+	if opc.Message == "" {
+		opc.Message = truncateString(g.Title, 130)
+	}
+	opc.Alias = g.DedupKey()
+	opc.Description = truncateString(g.Title+"\n"+g.Description, 15000)
+	opc.Entity = g.Field(FieldHost)
+	opc.Priority = opc.priorityMap[g.SeverityOrDefault()]
+	opc.Tags = append(opc.Tags, g.TagsOrGroups...)
+	if len(g.Fields) != 0 {
+		opc.Details = make(map[string]string, len(g.Fields))
+		for _, v := range g.Fields {
+			opc.Details[v.Name] = v.Value
+		}
+	}
+	return opc, verifier.Struct(opc)
+}
+
+func (opc *opsgeniePushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(opc)
+}
+
+func init() {
+	MustRegisterProvider(Opsgenie, func() PushProviderImpl { return &opsgeniePushProvider{} })
+}
+
+type opsgeniePushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is Alert API endpoint, https://api.opsgenie.com/v2/alerts by default,
+	// use https://api.eu.opsgenie.com/v2/alerts for EU instance
+	ProviderServerURL string                           `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *opsgeniePushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (o opsgeniePushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(o)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(o.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (o opsgeniePushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	opc := &opsgeniePushContent{}
+	opc.Init()
+	opc.AcceptExtParamSettings(o.ExtraParams)
+	return opc.FromGeneral(g)
+}
+
+func (o opsgeniePushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*opsgeniePushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := o.ProviderServerURL
+	if apiURL == "" {
+		apiURL = defaultOpsgenieAlertsURL
+	}
+	respData, statusCode, err := o.SendRequest(ctx, Opsgenie, &HTTPRequest{
+		Method: http.MethodPost,
+		URL:    apiURL,
+		Header: http.Header{
+			"Content-Type":  {postJSONContentType},
+			"Authorization": {"GenieKey " + o.ExtraParams.APIKey},
+		},
+		Body: body,
+	})
+	if err != nil {
+		return nil, parseOpsgenieError(err)
+	}
+	opr := &opsgeniePushResponse{}
+	err = json.Unmarshal(respData, opr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("%s, RequestID: %s", opr.Result, opr.RequestID),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// opsgeniePushResponse is returned with 202, alert is created asynchronously
+type opsgeniePushResponse struct {
+	Result    string            `json:"result"`
+	Message   string            `json:"message,omitempty"`
+	Took      float64           `json:"took"`
+	RequestID string            `json:"requestId"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// parseOpsgenieError turns error response of Alert API into *ProviderError, RequestID is kept for support cases
+func parseOpsgenieError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	opr := &opsgeniePushResponse{}
+	_ = json.Unmarshal(statusErr.Body, opr)
+	msg := opr.Message
+	if len(opr.Errors) != 0 {
+		msg = fmt.Sprintf("%s %v", msg, opr.Errors)
+	}
+	if opr.RequestID != "" {
+		msg = fmt.Sprintf("%s, RequestID: %s", msg, opr.RequestID)
+	}
+	if statusErr.StatusCode == http.StatusTooManyRequests {
+		msg = fmt.Sprintf("%s retry after %s", msg, statusErr.RetryAfter)
+	}
+	return &ProviderError{
+		Provider: Opsgenie,
+		Kind:     errorKindByStatus(statusErr.StatusCode),
+		Code:     statusErr.StatusCode,
+		Message:  strings.TrimSpace(msg),
+		Err:      err,
+	}
+}
+
+type opsgeniePushProviderExtraParams struct {
+	// APIKey is the key of an API integration, sent as GenieKey
+	APIKey     string              `json:"apiKey" validate:"required"`
+	Responders []opsgenieResponder `json:"responders,omitempty" validate:"omitempty,max=50,dive"`
+	// Tags are added besides GeneralPushContent.TagsOrGroups
+	Tags []string `json:"tags,omitempty" validate:"omitempty,max=20"`
+	// PriorityMap overrides defaultOpsgeniePriority
+	PriorityMap map[Severity]string `json:"priorityMap,omitempty" validate:"omitempty,dive,keys,oneof=info warning error critical,endkeys,oneof=P1 P2 P3 P4 P5"`
+}
@@ -0,0 +1,29 @@
+package pushsdk
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestOpsgenieMessageWithinLimit(t *testing.T) {
+	prv := opsgeniePushProvider{ExtraParams: &opsgeniePushProviderExtraParams{APIKey: "key"}}
+	cases := []*GeneralPushContent{
+		{Title: "title", ShortTitle: strings.Repeat("s", 200)},
+		{Title: strings.Repeat("登", 200)},
+		{Title: "title", ShortTitle: "short", Description: strings.Repeat("d", 20000)},
+	}
+	for _, g := range cases {
+		spc, err := prv.TransformToSpecificPushContent(g)
+		if err != nil {
+			t.Fatalf("transform: %v", err)
+		}
+		opc := spc.(*opsgeniePushContent)
+		if n := utf8.RuneCountInString(opc.Message); n > 130 {
+			t.Errorf("message has %d characters, want at most 130", n)
+		}
+		if n := utf8.RuneCountInString(opc.Description); n > 15000 {
+			t.Errorf("description has %d characters, want at most 15000", n)
+		}
+	}
+}
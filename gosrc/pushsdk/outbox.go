@@ -0,0 +1,375 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"rdpalert/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	outboxEntrySuffix   = ".json"
+	outboxClaimedSuffix = ".sending"
+	// claimed entries older than this are treated as left over by a crashed process
+	outboxStaleClaimAge = time.Hour
+)
+
+var (
+	ErrOutboxEntryClaimed = errors.New("outbox entry is claimed by another process")
+	ErrOutboxIsNotSet     = errors.New("outbox is not attached")
+)
+
+// OutboxConfig controls the on-disk spool for alerts that could not be delivered
+type OutboxConfig struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// MaxAge drops entries older than it, default 72h
+	MaxAge Duration `json:"maxAge,omitempty" validate:"omitempty,gte=0"`
+	// MaxEntries keeps only the newest entries, default 100
+	MaxEntries int `json:"maxEntries,omitempty" validate:"omitempty,gte=0"`
+	// MaxTotalBytes keeps total size of the spool below it by dropping oldest entries, default 4MiB
+	MaxTotalBytes int64 `json:"maxTotalBytes,omitempty" validate:"omitempty,gte=0"`
+}
+
+// OutboxEntry is a failed push content persisted with the providers it still needs to reach
+type OutboxEntry struct {
+	ID        string              `json:"id"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Attempts  int                 `json:"attempts"`
+	Providers []PushProvider      `json:"providers"`
+	LastError string              `json:"lastError,omitempty"`
+	Content   *GeneralPushContent `json:"content"`
+}
+
+// Outbox is a directory based spool, every entry is stored as a single json file
+type Outbox struct {
+	dir  string
+	conf OutboxConfig
+}
+
+// NewOutbox creates spool directory if necessary, conf could be nil for defaults
+func NewOutbox(dir string, conf *OutboxConfig) (*Outbox, error) {
+	o := &Outbox{
+		dir: dir,
+		conf: OutboxConfig{
+			MaxAge:        Duration(72 * time.Hour),
+			MaxEntries:    100,
+			MaxTotalBytes: 4194304,
+		},
+	}
+	if conf != nil {
+		o.conf.Disabled = conf.Disabled
+		if conf.MaxAge != 0 {
+			o.conf.MaxAge = conf.MaxAge
+		}
+		if conf.MaxEntries != 0 {
+			o.conf.MaxEntries = conf.MaxEntries
+		}
+		if conf.MaxTotalBytes != 0 {
+			o.conf.MaxTotalBytes = conf.MaxTotalBytes
+		}
+	}
+	if o.conf.Disabled {
+		return o, nil
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *Outbox) Disabled() bool {
+	return o.conf.Disabled
+}
+
+func newOutboxEntryID() string {
+	rb := make([]byte, 4)
+	_, _ = rand.Read(rb)
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(rb))
+}
+
+// Put writes entry to spool atomically, then enforces size cap
+func (o *Outbox) Put(e *OutboxEntry) error {
+	if o.conf.Disabled {
+		return nil
+	}
+	if e.ID == "" {
+		e.ID = newOutboxEntryID()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(o.dir, e.ID+".tmp")
+	err = os.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpPath, filepath.Join(o.dir, e.ID+outboxEntrySuffix))
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return o.prune()
+}
+
+// List returns all live entries sorted from oldest, expired and corrupted entries are removed
+func (o *Outbox) List() ([]*OutboxEntry, error) {
+	if o.conf.Disabled {
+		return nil, nil
+	}
+	gLogger, err := utils.GetLoggerInstance()
+	if err != nil {
+		return nil, err
+	}
+	o.recoverStaleClaims()
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*OutboxEntry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), outboxEntrySuffix) {
+			continue
+		}
+		fPath := filepath.Join(o.dir, f.Name())
+		data, err := os.ReadFile(fPath)
+		if err != nil {
+			gLogger.Warn("Outbox: read entry failed: ", fPath, err.Error())
+			continue
+		}
+		e := &OutboxEntry{}
+		err = json.Unmarshal(data, e)
+		if err != nil || e.Content == nil {
+			gLogger.Warn("Outbox: drop corrupted entry: ", fPath)
+			_ = os.Remove(fPath)
+			continue
+		}
+		if o.conf.MaxAge > 0 && time.Since(e.CreatedAt) > time.Duration(o.conf.MaxAge) {
+			gLogger.Warn("Outbox: drop expired entry: ", e.ID, e.CreatedAt.Format(time.RFC3339))
+			_ = os.Remove(fPath)
+			continue
+		}
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].CreatedAt.Before(res[j].CreatedAt) })
+	return res, nil
+}
+
+// Claim marks entry as being delivered, so concurrent invocation won't send it twice
+func (o *Outbox) Claim(e *OutboxEntry) error {
+	err := os.Rename(filepath.Join(o.dir, e.ID+outboxEntrySuffix), filepath.Join(o.dir, e.ID+outboxClaimedSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrOutboxEntryClaimed
+	}
+	return err
+}
+
+// Remove deletes a claimed entry after successful delivery
+func (o *Outbox) Remove(id string) error {
+	err := os.Remove(filepath.Join(o.dir, id+outboxClaimedSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// recoverStaleClaims puts entries claimed by a crashed process back to spool
+func (o *Outbox) recoverStaleClaims() {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), outboxClaimedSuffix) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil || time.Since(info.ModTime()) < outboxStaleClaimAge {
+			continue
+		}
+		fPath := filepath.Join(o.dir, f.Name())
+		_ = os.Rename(fPath, strings.TrimSuffix(fPath, outboxClaimedSuffix)+outboxEntrySuffix)
+	}
+}
+
+// prune drops oldest entries until both entry count and total size are within cap
+func (o *Outbox) prune() error {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return err
+	}
+	type spooled struct {
+		path string
+		size int64
+	}
+	entries := make([]spooled, 0, len(files))
+	var totalSize int64
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), outboxEntrySuffix) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, spooled{path: filepath.Join(o.dir, f.Name()), size: info.Size()})
+		totalSize += info.Size()
+	}
+	// entry ID starts with creation time in nanoseconds, name order is creation order
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	for len(entries) > 0 &&
+		((o.conf.MaxEntries > 0 && len(entries) > o.conf.MaxEntries) ||
+			(o.conf.MaxTotalBytes > 0 && totalSize > o.conf.MaxTotalBytes)) {
+		_ = os.Remove(entries[0].path)
+		totalSize -= entries[0].size
+		entries = entries[1:]
+	}
+	return nil
+}
+
+// AttachOutbox lets pusher spool failed content into o and deliver it with FlushOutbox
+func (p *pusher) AttachOutbox(o *Outbox) {
+	p.outbox = o
+}
+
+// spoolFailures persists g for every failed provider in report, prev is the entry g comes from
+func (p *pusher) spoolFailures(g *GeneralPushContent, report *PushReport, prev *OutboxEntry) {
+	if p.outbox == nil || p.outbox.Disabled() {
+		return
+	}
+	gLogger, _ := utils.GetLoggerInstance()
+	failed := report.Failed()
+	// copy content as delivered targets are recorded into it
+	content := *g
+	content.Delivered = maps.Clone(g.Delivered)
+	e := &OutboxEntry{
+		Attempts:  1,
+		Providers: make([]PushProvider, 0, len(failed)),
+		LastError: report.Err().Error(),
+		Content:   &content,
+	}
+	if prev != nil {
+		e.ID = prev.ID
+		e.CreatedAt = prev.CreatedAt
+		e.Attempts = prev.Attempts + 1
+	}
+	for _, v := range failed {
+		// retrying won't help if service rejected it for good, e.g. invalid device key
+		if permanentFailure(v.Err) {
+			gLogger.Warn("Outbox: not spooled for permanent failure: ", v.Provider, v.Err.Error())
+			continue
+		}
+		e.Providers = append(e.Providers, v.Provider)
+		// targets reached this time must not get the same alert again on replay
+		var partialErr *PartialDeliveryError
+		if errors.As(v.Err, &partialErr) {
+			if content.Delivered == nil {
+				content.Delivered = make(map[PushProvider][]string, 1)
+			}
+			content.Delivered[v.Provider] = partialErr.Delivered
+		}
+	}
+	if len(e.Providers) == 0 {
+		return
+	}
+	err := p.outbox.Put(e)
+	if err != nil {
+		gLogger.Error("Outbox: failed to spool undelivered content: ", err.Error())
+		return
+	}
+	gLogger.Info("Outbox: undelivered content spooled: ", e.ID, e.Providers)
+}
+
+// permanentFailure tells whether err is a *ProviderError that retrying won't help,
+// errors of several targets are joined by provider, all of them must be permanent then
+func permanentFailure(err error) bool {
+	var partialErr *PartialDeliveryError
+	if errors.As(err, &partialErr) {
+		err = partialErr.Err
+	}
+	if provErr, ok := err.(*ProviderError); ok {
+		return !provErr.Temporary()
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, v := range errs {
+			if !permanentFailure(v) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	var provErr *ProviderError
+	return errors.As(err, &provErr) && !provErr.Temporary()
+}
+
+// FlushOutbox tries to deliver every spooled entry to the providers it failed on,
+// entries still failing are written back with attempt count increased
+func (p *pusher) FlushOutbox(ctx context.Context) (int, error) {
+	gLogger, err := utils.GetLoggerInstance()
+	if err != nil {
+		return 0, err
+	}
+	if p.outbox == nil {
+		return 0, ErrOutboxIsNotSet
+	}
+	if p.Config.IsDryRun {
+		gLogger.Info("Config Is Set To DryRun, Outbox will not be flushed.")
+		return 0, nil
+	}
+	entries, err := p.outbox.List()
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	errs := make([]error, 0)
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		err = p.outbox.Claim(e)
+		if err != nil {
+			if !errors.Is(err, ErrOutboxEntryClaimed) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		// provider may be removed from config since the entry is spooled
+		targets := make([]PushProvider, 0, len(e.Providers))
+		for _, v := range e.Providers {
+			if _, ok := p.providers[v]; ok {
+				targets = append(targets, v)
+			} else {
+				gLogger.Warn("Outbox: provider is no longer configured, skipped: ", e.ID, v)
+			}
+		}
+		gLogger.Info("Outbox: delivering spooled entry: ", e.ID, targets)
+		report := p.deliver(ctx, e.Content, targets)
+		if len(report.Failed()) == 0 {
+			delivered++
+			err = p.outbox.Remove(e.ID)
+		} else {
+			errs = append(errs, fmt.Errorf("outbox entry %s: %w", e.ID, report.Err()))
+			p.spoolFailures(e.Content, report, e)
+			err = p.outbox.Remove(e.ID)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
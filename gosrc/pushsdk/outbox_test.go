@@ -0,0 +1,229 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestOutbox(t *testing.T, conf *OutboxConfig) *Outbox {
+	t.Helper()
+	o, err := NewOutbox(t.TempDir(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// putEntries spools n entries one after another, IDs are returned oldest first
+func putEntries(t *testing.T, o *Outbox, n int) []string {
+	t.Helper()
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		e := &OutboxEntry{Providers: []PushProvider{"fake"}, Content: &GeneralPushContent{Title: "alert"}}
+		if err := o.Put(e); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, e.ID)
+		// entry ID starts with creation time in nanoseconds
+		time.Sleep(time.Millisecond)
+	}
+	return ids
+}
+
+func listIDs(t *testing.T, o *Outbox) []string {
+	t.Helper()
+	entries, err := o.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, v := range entries {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func TestOutboxPruneByEntries(t *testing.T) {
+	o := newTestOutbox(t, &OutboxConfig{MaxEntries: 2})
+	ids := putEntries(t, o, 3)
+	if got := listIDs(t, o); !slices.Equal(got, ids[1:]) {
+		t.Errorf("entries = %v, want the newest two %v", got, ids[1:])
+	}
+}
+
+func TestOutboxPruneByTotalBytes(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	ids := putEntries(t, o, 1)
+	info, err := os.Stat(filepath.Join(o.dir, ids[0]+outboxEntrySuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// room for two entries and a half
+	o.conf.MaxTotalBytes = info.Size()*5/2 + 1
+	ids = append(ids, putEntries(t, o, 2)...)
+	if got := listIDs(t, o); !slices.Equal(got, ids[1:]) {
+		t.Errorf("entries = %v, want the newest two %v", got, ids[1:])
+	}
+}
+
+func TestOutboxExpiredEntries(t *testing.T) {
+	o := newTestOutbox(t, &OutboxConfig{MaxAge: Duration(time.Hour)})
+	expired := &OutboxEntry{CreatedAt: time.Now().Add(-2 * time.Hour), Content: &GeneralPushContent{Title: "old"}}
+	if err := o.Put(expired); err != nil {
+		t.Fatal(err)
+	}
+	ids := putEntries(t, o, 1)
+	if got := listIDs(t, o); !slices.Equal(got, ids) {
+		t.Errorf("entries = %v, want only %v", got, ids)
+	}
+	if _, err := os.Stat(filepath.Join(o.dir, expired.ID+outboxEntrySuffix)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expired entry is not removed: %v", err)
+	}
+}
+
+func TestOutboxClaim(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	putEntries(t, o, 1)
+	entries, err := o.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Claim(entries[0]); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if err := o.Claim(entries[0]); !errors.Is(err, ErrOutboxEntryClaimed) {
+		t.Errorf("second claim = %v, want %v", err, ErrOutboxEntryClaimed)
+	}
+	if got := listIDs(t, o); len(got) != 0 {
+		t.Errorf("claimed entry is listed: %v", got)
+	}
+}
+
+func TestOutboxRecoverStaleClaims(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	ids := putEntries(t, o, 2)
+	entries, err := o.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range entries {
+		if err := o.Claim(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first claim is left by a process crashed long ago, the second one is still being delivered
+	old := time.Now().Add(-outboxStaleClaimAge - time.Minute)
+	if err := os.Chtimes(filepath.Join(o.dir, ids[0]+outboxClaimedSuffix), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if got := listIDs(t, o); !slices.Equal(got, ids[:1]) {
+		t.Errorf("entries = %v, want only the stale claim %v", got, ids[:1])
+	}
+}
+
+func TestOutboxDropCorrupted(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	ids := putEntries(t, o, 1)
+	for name, data := range map[string]string{"1-broken.json": `{"id":`, "2-nocontent.json": `{"id":"2-nocontent"}`} {
+		if err := os.WriteFile(filepath.Join(o.dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := listIDs(t, o); !slices.Equal(got, ids) {
+		t.Errorf("entries = %v, want only %v", got, ids)
+	}
+	for _, name := range []string{"1-broken.json", "2-nocontent.json"} {
+		if _, err := os.Stat(filepath.Join(o.dir, name)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("corrupted entry %s is not removed: %v", name, err)
+		}
+	}
+}
+
+func TestFlushOutboxRespool(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	prv := &fakeProvider{err: errors.New("connection refused")}
+	p := &pusher{Config: &PushConfig{}, providers: map[PushProvider]PushProviderImpl{"fake": prv}, outbox: o}
+	ids := putEntries(t, o, 1)
+	before, _ := o.List()
+
+	flushed, err := p.FlushOutbox(context.Background())
+	if err == nil || flushed != 0 {
+		t.Fatalf("flush = %d, %v, want failure", flushed, err)
+	}
+	entries, err := o.List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %d, %v", len(entries), err)
+	}
+	e := entries[0]
+	if e.ID != ids[0] || e.Attempts != before[0].Attempts+1 || !e.CreatedAt.Equal(before[0].CreatedAt) {
+		t.Errorf("respooled entry = %+v, want same ID and creation time with attempts increased", e)
+	}
+	if e.LastError == "" || !slices.Equal(e.Providers, []PushProvider{"fake"}) {
+		t.Errorf("respooled entry = %+v", e)
+	}
+
+	prv.setErr(nil)
+	flushed, err = p.FlushOutbox(context.Background())
+	if err != nil || flushed != 1 {
+		t.Fatalf("flush = %d, %v", flushed, err)
+	}
+	if got := listIDs(t, o); len(got) != 0 {
+		t.Errorf("delivered entry is still spooled: %v", got)
+	}
+}
+
+func TestPermanentFailure(t *testing.T) {
+	invalidKey := &ProviderError{Provider: Pushbullet, Kind: ErrInvalidDeviceKey, Code: 404}
+	serverErr := &ProviderError{Provider: Pushbullet, Kind: ErrServerError, Code: 502}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"permanent", invalidKey, true},
+		{"temporary", serverErr, false},
+		{"network", errors.New("connection reset"), false},
+		{"wrapped permanent", fmt.Errorf("target a: %w", invalidKey), true},
+		{"all targets permanent", errors.Join(fmt.Errorf("target a: %w", invalidKey), fmt.Errorf("target b: %w", invalidKey)), true},
+		{"one target temporary", errors.Join(fmt.Errorf("target a: %w", invalidKey), fmt.Errorf("target b: %w", serverErr)), false},
+		{"partial with temporary", &PartialDeliveryError{Delivered: []string{"c"},
+			Err: errors.Join(fmt.Errorf("target a: %w", invalidKey), fmt.Errorf("target b: %w", serverErr))}, false},
+		{"partial all permanent", &PartialDeliveryError{Delivered: []string{"c"},
+			Err: errors.Join(fmt.Errorf("target a: %w", invalidKey))}, true},
+	}
+	for _, c := range cases {
+		if got := permanentFailure(c.err); got != c.want {
+			t.Errorf("%s: permanent = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSpoolFailuresPartialDelivery(t *testing.T) {
+	o := newTestOutbox(t, nil)
+	// one device is gone for good while the other hit a server error, only the latter is worth a retry
+	prv := &fakeProvider{err: &PartialDeliveryError{
+		Delivered: []string{"dev-ok"},
+		Err: errors.Join(
+			fmt.Errorf("target dev-gone: %w", &ProviderError{Provider: "fake", Kind: ErrInvalidDeviceKey, Code: 404}),
+			fmt.Errorf("target dev-busy: %w", &ProviderError{Provider: "fake", Kind: ErrServerError, Code: 503}),
+		),
+	}}
+	p := &pusher{Config: &PushConfig{}, providers: map[PushProvider]PushProviderImpl{"fake": prv}, outbox: o}
+	p.StageGeneralPushContent(&GeneralPushContent{Title: "RDP Login - Success"})
+	if _, err := p.SendPush(context.Background()); err == nil {
+		t.Fatal("send push succeeded, want failure")
+	}
+	entries, err := o.List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %d, %v, want the partial failure spooled", len(entries), err)
+	}
+	if got := entries[0].Content.Delivered["fake"]; !slices.Equal(got, []string{"dev-ok"}) {
+		t.Errorf("delivered targets = %v", got)
+	}
+}
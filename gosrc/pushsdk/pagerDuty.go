@@ -0,0 +1,214 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// defaultPagerDutySeverity keeps the severity as-is, PagerDuty accepts the same four levels
+var defaultPagerDutySeverity = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// pagerDutyPushContent is a trigger event of Events API v2, RoutingKey is the integration key of service
+type pagerDutyPushContent struct {
+	RoutingKey  string           `json:"routing_key" validate:"required"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key,omitempty"`
+	Payload     pagerDutyPayload `json:"payload"`
+	Client      string           `json:"client,omitempty"`
+	ClientURL   string           `json:"client_url,omitempty"`
+
+	severityMap map[Severity]string
+	// provider info
+	providerName PushProvider
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary" validate:"required,max=1024"`
+	Source        string            `json:"source" validate:"required"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (ppc *pagerDutyPushContent) Init() {
+	ppc.EventAction = "trigger"
+	ppc.Client = "RDPAlert"
+	ppc.severityMap = defaultPagerDutySeverity
+	ppc.SetPushProvider()
+}
+
+func (ppc *pagerDutyPushContent) Provider() PushProvider {
+	return ppc.providerName
+}
+
+func (ppc *pagerDutyPushContent) SetPushProvider() {
+	ppc.providerName = PagerDuty
+}
+
+func (ppc *pagerDutyPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*pagerDutyPushProviderExtraParams)
+	ppc.RoutingKey = d1.RoutingKey
+	ppc.ClientURL = d1.ClientURL
+	ppc.Payload.Component = d1.Component
+	ppc.Payload.Group = d1.Group
+	ppc.Payload.Class = d1.Class
+	if len(d1.SeverityMap) != 0 {
+		ppc.severityMap = make(map[Severity]string, len(defaultPagerDutySeverity))
+		for k, v := range defaultPagerDutySeverity {
+			ppc.severityMap[k] = v
+		}
+		for k, v := range d1.SeverityMap {
+			ppc.severityMap[k] = v
+		}
+	}
+}
+
+// FromGeneral puts structured login fields in custom_details, source falls back to title if host is unknown
+func (ppc *pagerDutyPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	ppc.DedupKey = g.DedupKey()
+	ppc.Payload.Summary = truncateString(g.Title+": "+g.ShortTitle, 1024)
+	ppc.Payload.Source = g.Field(FieldHost)
+	if ppc.Payload.Source == "" {
+		ppc.Payload.Source = g.Title
+	}
+	ppc.Payload.Severity = ppc.severityMap[g.SeverityOrDefault()]
+	ppc.Payload.Timestamp = g.OccurredAtOrNow().Format(time.RFC3339)
+	ppc.Payload.CustomDetails = make(map[string]string, len(g.Fields)+1)
+	for _, v := range g.Fields {
+		ppc.Payload.CustomDetails[v.Name] = v.Value
+	}
+	if len(g.Fields) == 0 {
+		ppc.Payload.CustomDetails["Description"] = g.Description
+	}
+	return ppc, verifier.Struct(ppc.Payload)
+}
+
+func (ppc *pagerDutyPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(ppc)
+}
+
+func init() {
+	MustRegisterProvider(PagerDuty, func() PushProviderImpl { return &pagerDutyPushProvider{} })
+}
+
+type pagerDutyPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is Events API endpoint, https://events.pagerduty.com/v2/enqueue by default
+	ProviderServerURL string                            `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *pagerDutyPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (p pagerDutyPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(p)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(p.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (p pagerDutyPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	ppc := &pagerDutyPushContent{}
+	ppc.Init()
+	ppc.AcceptExtParamSettings(p.ExtraParams)
+	return ppc.FromGeneral(g)
+}
+
+func (p pagerDutyPushProvider) SendPushContent(ctx context.Context, pc PushContent) (*PushResponse, error) {
+	pData := pc.(*pagerDutyPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := p.ProviderServerURL
+	if apiURL == "" {
+		apiURL = defaultPagerDutyEventsURL
+	}
+	respData, statusCode, err := p.SendRequest(ctx, PagerDuty, &HTTPRequest{
+		Method: http.MethodPost,
+		URL:    apiURL,
+		Header: http.Header{"Content-Type": {postJSONContentType}},
+		Body:   body,
+	})
+	if err != nil {
+		return nil, parsePagerDutyError(err)
+	}
+	ppr := &pagerDutyPushResponse{}
+	err = json.Unmarshal(respData, ppr)
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   fmt.Sprintf("%s: %s, DedupKey: %s", ppr.Status, ppr.Message, ppr.DedupKey),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// pagerDutyPushResponse is returned with 202 on success, and 400 with Errors for invalid event
+type pagerDutyPushResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message"`
+	DedupKey string   `json:"dedup_key"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// parsePagerDutyError turns error response of Events API into *ProviderError
+func parsePagerDutyError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	ppr := &pagerDutyPushResponse{}
+	_ = json.Unmarshal(statusErr.Body, ppr)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	msg := ppr.Message
+	if len(ppr.Errors) != 0 {
+		msg = fmt.Sprintf("%s %v", msg, ppr.Errors)
+		// routing key of a deleted or disabled integration
+		if slices.ContainsFunc(ppr.Errors, func(s string) bool { return strings.Contains(strings.ToLower(s), "routing key") }) {
+			kind = ErrInvalidDeviceKey
+		}
+	}
+	if statusErr.StatusCode == http.StatusTooManyRequests {
+		msg = fmt.Sprintf("%s retry after %s", msg, statusErr.RetryAfter)
+	}
+	return &ProviderError{
+		Provider: PagerDuty,
+		Kind:     kind,
+		Code:     statusErr.StatusCode,
+		Message:  strings.TrimSpace(msg),
+		Err:      err,
+	}
+}
+
+type pagerDutyPushProviderExtraParams struct {
+	// RoutingKey is the integration key of an Events API v2 integration
+	RoutingKey string `json:"routingKey" validate:"required,len=32"`
+	// SeverityMap overrides defaultPagerDutySeverity
+	SeverityMap map[Severity]string `json:"severityMap,omitempty" validate:"omitempty,dive,keys,oneof=info warning error critical,endkeys,oneof=info warning error critical"`
+	Component   string              `json:"component,omitempty" validate:"omitempty"`
+	Group       string              `json:"group,omitempty" validate:"omitempty"`
+	Class       string              `json:"class,omitempty" validate:"omitempty"`
+	// ClientURL is shown as a link in the incident, e.g. jump-host portal
+	ClientURL string `json:"clientURL,omitempty" validate:"omitempty,url"`
+}
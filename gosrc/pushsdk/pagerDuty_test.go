@@ -0,0 +1,24 @@
+package pushsdk
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPagerDutySummaryWithinLimit(t *testing.T) {
+	prv := pagerDutyPushProvider{ExtraParams: &pagerDutyPushProviderExtraParams{RoutingKey: strings.Repeat("a", 32)}}
+	for _, title := range []string{strings.Repeat("t", 2000), strings.Repeat("登", 2000)} {
+		spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: title, ShortTitle: "short"})
+		if err != nil {
+			t.Fatalf("transform: %v", err)
+		}
+		summary := spc.(*pagerDutyPushContent).Payload.Summary
+		if n := utf8.RuneCountInString(summary); n != 1024 {
+			t.Errorf("summary has %d characters, want 1024", n)
+		}
+		if !strings.HasSuffix(summary, "...") {
+			t.Errorf("summary is not marked as truncated: %q", summary[len(summary)-10:])
+		}
+	}
+}
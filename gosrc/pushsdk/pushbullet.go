@@ -0,0 +1,230 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPushbulletPushesURL = "https://api.pushbullet.com/v2/pushes"
+
+// pushbulletPushContent is a note push, DeviceIden and ChannelTag are set per target
+type pushbulletPushContent struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Body       string `json:"body" validate:"required"`
+	DeviceIden string `json:"device_iden,omitempty"`
+	ChannelTag string `json:"channel_tag,omitempty"`
+
+	// delivered are targets reached by previous attempt, they are skipped
+	delivered []string
+	// provider info
+	providerName PushProvider
+}
+
+func (ppc *pushbulletPushContent) Init() {
+	ppc.Type = "note"
+	ppc.SetPushProvider()
+}
+
+func (ppc *pushbulletPushContent) Provider() PushProvider {
+	return ppc.providerName
+}
+
+func (ppc *pushbulletPushContent) SetPushProvider() {
+	ppc.providerName = Pushbullet
+}
+
+func (ppc *pushbulletPushContent) AcceptExtParamSettings(_ any) {
+}
+
+func (ppc *pushbulletPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	ppc.Title = g.Title
+	ppc.delivered = g.DeliveredTargets()
+	if len(g.Fields) == 0 {
+		ppc.Body = g.Description
+		return ppc, nil
+	}
+	lines := make([]string, 0, len(g.Fields))
+	for _, v := range g.Fields {
+		lines = append(lines, v.Name+": "+v.Value)
+	}
+	ppc.Body = g.ShortTitle + "\n\n" + strings.Join(lines, "\n")
+	return ppc, nil
+}
+
+func (ppc *pushbulletPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(ppc)
+}
+
+func init() {
+	MustRegisterProvider(Pushbullet, func() PushProviderImpl { return &pushbulletPushProvider{} })
+}
+
+type pushbulletPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is pushes API endpoint, https://api.pushbullet.com/v2/pushes by default
+	ProviderServerURL string                             `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *pushbulletPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (p pushbulletPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(p)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(p.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (p pushbulletPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	ppc := &pushbulletPushContent{}
+	ppc.Init()
+	ppc.AcceptExtParamSettings(p.ExtraParams)
+	return ppc.FromGeneral(g)
+}
+
+// SendPushContent pushes to every target, all devices of the account if no target is configured,
+// *PartialDeliveryError tells which targets are reached if some of them failed
+func (p pushbulletPushProvider) SendPushContent(ctx context.Context, pc PushContent) (*PushResponse, error) {
+	pData := pc.(*pushbulletPushContent)
+	apiURL := p.ProviderServerURL
+	if apiURL == "" {
+		apiURL = defaultPushbulletPushesURL
+	}
+	targets := p.ExtraParams.Targets
+	if len(targets) == 0 {
+		targets = []pushbulletTarget{{}}
+	}
+	sent := make([]string, 0, len(targets))
+	delivered := slices.Clone(pData.delivered)
+	errs := make([]error, 0)
+	for _, target := range targets {
+		if slices.Contains(pData.delivered, target.String()) {
+			continue
+		}
+		msg := *pData
+		msg.DeviceIden = target.DeviceIden
+		msg.ChannelTag = target.ChannelTag
+		body, err := msg.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		respData, _, err := p.SendRequest(ctx, Pushbullet, &HTTPRequest{
+			Method: http.MethodPost,
+			URL:    apiURL,
+			Header: http.Header{
+				"Content-Type": {postJSONContentType},
+				"Access-Token": {p.ExtraParams.AccessToken},
+			},
+			Body:       body,
+			RetryAfter: parsePushbulletRetryAfter,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", target.String(), parsePushbulletError(err)))
+			continue
+		}
+		ppr := &pushbulletPushResponse{}
+		err = json.Unmarshal(respData, ppr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", target.String(), err))
+			continue
+		}
+		sent = append(sent, ppr.Iden)
+		delivered = append(delivered, target.String())
+	}
+	if len(errs) != 0 {
+		if len(delivered) != 0 {
+			return nil, &PartialDeliveryError{Delivered: delivered, Err: errors.Join(errs...)}
+		}
+		return nil, errors.Join(errs...)
+	}
+	return &PushResponse{
+		Code:      http.StatusOK,
+		Message:   "Sent PushIdens: " + strings.Join(sent, ", "),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+type pushbulletPushResponse struct {
+	Iden    string  `json:"iden"`
+	Created float64 `json:"created"`
+}
+
+// pushbulletErrorResponse is returned with 4xx and 5xx, check: https://docs.pushbullet.com/#errors
+type pushbulletErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parsePushbulletRetryAfter reads X-Ratelimit-Reset, which is a unix timestamp
+func parsePushbulletRetryAfter(header http.Header, _ []byte) time.Duration {
+	reset, err := strconv.ParseInt(header.Get("X-Ratelimit-Reset"), 10, 64)
+	if err != nil {
+		return parseRetryAfter(header.Get("Retry-After"))
+	}
+	d := time.Until(time.Unix(reset, 0))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// parsePushbulletError turns error response into *ProviderError by error code
+func parsePushbulletError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	per := &pushbulletErrorResponse{}
+	_ = json.Unmarshal(statusErr.Body, per)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch per.Error.Code {
+	case "invalid_access_token":
+		kind = ErrAuthFailed
+	case "not_found", "invalid_device":
+		kind = ErrInvalidDeviceKey
+	}
+	return &ProviderError{
+		Provider: Pushbullet,
+		Kind:     kind,
+		Code:     statusErr.StatusCode,
+		Message:  strings.TrimSpace(per.Error.Code + " " + per.Error.Message),
+		Err:      err,
+	}
+}
+
+type pushbulletPushProviderExtraParams struct {
+	AccessToken string `json:"accessToken" validate:"required"`
+	// Targets are devices or channels to push to, all devices of the account if empty
+	Targets []pushbulletTarget `json:"targets,omitempty" validate:"omitempty,dive"`
+}
+
+// pushbulletTarget is either a device or a channel owned by the account
+type pushbulletTarget struct {
+	DeviceIden string `json:"deviceIden,omitempty" validate:"required_without=ChannelTag,excluded_with=ChannelTag"`
+	ChannelTag string `json:"channelTag,omitempty" validate:"omitempty"`
+}
+
+func (t pushbulletTarget) String() string {
+	switch {
+	case t.DeviceIden != "":
+		return "device " + t.DeviceIden
+	case t.ChannelTag != "":
+		return "channel " + t.ChannelTag
+	default:
+		return "all devices"
+	}
+}
@@ -0,0 +1,64 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestPushbulletSkipsDeliveredTargets(t *testing.T) {
+	var got []string
+	failing := "ujpah72o0sjAoRtnM0jc"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &pushbulletPushContent{}
+		_ = json.NewDecoder(r.Body).Decode(msg)
+		got = append(got, msg.DeviceIden+msg.ChannelTag)
+		if msg.DeviceIden == failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"code":"server_error","type":"server","message":"try again"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"iden":"ujpah72o0sjAoRtnM0jc","created":1700000000.1}`))
+	}))
+	defer srv.Close()
+	prv := &pushbulletPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL, ExtraParams: &pushbulletPushProviderExtraParams{
+		AccessToken: "o.token",
+		Targets:     []pushbulletTarget{{DeviceIden: "ujEHvrk2tiA"}, {DeviceIden: failing}, {ChannelTag: "rdpalert"}},
+	}}
+	g := &GeneralPushContent{Title: "RDP Login - Success", Description: "alice from 10.0.0.8"}
+	g.SetSpecificPushProvider(Pushbullet)
+	send := func() error {
+		spc, err := prv.TransformToSpecificPushContent(g)
+		if err != nil {
+			t.Fatalf("transform: %v", err)
+		}
+		_, err = prv.SendPushContent(context.Background(), spc)
+		return err
+	}
+
+	var partialErr *PartialDeliveryError
+	if err := send(); !errors.As(err, &partialErr) {
+		t.Fatalf("want *PartialDeliveryError, got %v", err)
+	}
+	if !errors.Is(partialErr, ErrServerError) {
+		t.Errorf("failed target should keep its provider error: %v", partialErr)
+	}
+	if want := []string{"device ujEHvrk2tiA", "channel rdpalert"}; !slices.Equal(partialErr.Delivered, want) {
+		t.Errorf("delivered = %v, want %v", partialErr.Delivered, want)
+	}
+
+	// replay as outbox does, only the failed device should be pushed again
+	got = nil
+	failing = ""
+	g.Delivered = map[PushProvider][]string{Pushbullet: partialErr.Delivered}
+	if err := send(); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !slices.Equal(got, []string{"ujpah72o0sjAoRtnM0jc"}) {
+		t.Errorf("replay pushed to %v", got)
+	}
+}
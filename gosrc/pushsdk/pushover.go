@@ -0,0 +1,245 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultPushoverMessagesURL = "https://api.pushover.net/1/messages.json"
+
+	pushoverEmergencyPriority = 2
+	// emergency notification is repeated every retry seconds until acknowledged or expired
+	defaultPushoverRetry  = 60
+	defaultPushoverExpire = 3600
+)
+
+// defaultPushoverPriority maps Severity to Pushover priority, -2 is lowest and 2 is emergency
+var defaultPushoverPriority = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityError:    1,
+	SeverityCritical: 2,
+}
+
+// pushoverPushContent is an instance of https://pushover.net/api#messages, Token and User are set on sending
+type pushoverPushContent struct {
+	Token     string `json:"token"`
+	User      string `json:"user"`
+	Message   string `json:"message" validate:"required,max=1024"`
+	Title     string `json:"title,omitempty" validate:"max=250"`
+	Device    string `json:"device,omitempty"`
+	Priority  int    `json:"priority"`
+	Retry     int    `json:"retry,omitempty"`
+	Expire    int    `json:"expire,omitempty"`
+	HTML      int    `json:"html,omitempty"`
+	URL       string `json:"url,omitempty"`
+	URLTitle  string `json:"url_title,omitempty"`
+	Sound     string `json:"sound,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
+
+	priorityMap map[Severity]int
+	retry       int
+	expire      int
+	// provider info
+	providerName PushProvider
+}
+
+func (ppc *pushoverPushContent) Init() {
+	ppc.HTML = 1
+	ppc.priorityMap = defaultPushoverPriority
+	ppc.retry = defaultPushoverRetry
+	ppc.expire = defaultPushoverExpire
+	ppc.SetPushProvider()
+}
+
+func (ppc *pushoverPushContent) Provider() PushProvider {
+	return ppc.providerName
+}
+
+func (ppc *pushoverPushContent) SetPushProvider() {
+	ppc.providerName = Pushover
+}
+
+func (ppc *pushoverPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*pushoverPushProviderExtraParams)
+	ppc.Device = strings.Join(d1.Devices, ",")
+	ppc.URL = d1.URL
+	ppc.URLTitle = d1.URLTitle
+	ppc.Sound = d1.Sound
+	if d1.Retry != 0 {
+		ppc.retry = d1.Retry
+	}
+	if d1.Expire != 0 {
+		ppc.expire = d1.Expire
+	}
+	if len(d1.PriorityMap) != 0 {
+		ppc.priorityMap = make(map[Severity]int, len(defaultPushoverPriority))
+		for k, v := range defaultPushoverPriority {
+			ppc.priorityMap[k] = v
+		}
+		for k, v := range d1.PriorityMap {
+			ppc.priorityMap[k] = v
+		}
+	}
+}
+
+// FromGeneral renders message in Pushover HTML subset, retry and expire are only sent with emergency priority
+func (ppc *pushoverPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	ppc.Title = truncateString(g.Title, 250)
+	if len(g.Fields) == 0 {
+		ppc.Message = escapeHTMLWithin(g.Description, 1024)
+	} else {
+		// lines are added until limit is reached, the last one may be truncated
+		buf := &strings.Builder{}
+		for _, v := range g.Fields {
+			prefix := "<b>" + html.EscapeString(v.Name) + "</b>: "
+			if buf.Len() != 0 {
+				prefix = "\n" + prefix
+			}
+			left := 1024 - utf8.RuneCountInString(buf.String()) - utf8.RuneCountInString(prefix)
+			if left <= 0 {
+				break
+			}
+			buf.WriteString(prefix + escapeHTMLWithin(v.Value, left))
+		}
+		ppc.Message = buf.String()
+	}
+	ppc.Timestamp = g.OccurredAtOrNow().Unix()
+	ppc.Priority = ppc.priorityMap[g.SeverityOrDefault()]
+	if ppc.Priority == pushoverEmergencyPriority {
+		ppc.Retry = ppc.retry
+		ppc.Expire = ppc.expire
+	}
+	return ppc, nil
+}
+
+func (ppc *pushoverPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(ppc)
+}
+
+func init() {
+	MustRegisterProvider(Pushover, func() PushProviderImpl { return &pushoverPushProvider{} })
+}
+
+type pushoverPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is messages API endpoint, https://api.pushover.net/1/messages.json by default
+	ProviderServerURL string                           `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *pushoverPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (p pushoverPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(p)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(p.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (p pushoverPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	ppc := &pushoverPushContent{}
+	ppc.Init()
+	ppc.AcceptExtParamSettings(p.ExtraParams)
+	return ppc.FromGeneral(g)
+}
+
+func (p pushoverPushProvider) SendPushContent(ctx context.Context, pc PushContent) (*PushResponse, error) {
+	pData := pc.(*pushoverPushContent)
+	pData.Token = p.ExtraParams.AppToken
+	pData.User = p.ExtraParams.UserKey
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := p.ProviderServerURL
+	if apiURL == "" {
+		apiURL = defaultPushoverMessagesURL
+	}
+	respData, statusCode, err := p.SendRequest(ctx, Pushover, &HTTPRequest{
+		Method: http.MethodPost,
+		URL:    apiURL,
+		Header: http.Header{"Content-Type": {postJSONContentType}},
+		Body:   body,
+	})
+	if err != nil {
+		return nil, parsePushoverError(err)
+	}
+	ppr := &pushoverPushResponse{}
+	err = json.Unmarshal(respData, ppr)
+	if err != nil {
+		return nil, err
+	}
+	msg := "Request: " + ppr.Request
+	if ppr.Receipt != "" {
+		msg += ", Receipt: " + ppr.Receipt
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   msg,
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// pushoverPushResponse has status 1 on success, otherwise errors explain what's wrong and
+// the invalid parameter is marked, e.g. "user": "invalid"
+type pushoverPushResponse struct {
+	Status  int      `json:"status"`
+	Request string   `json:"request"`
+	Receipt string   `json:"receipt,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Token   string   `json:"token,omitempty"`
+	User    string   `json:"user,omitempty"`
+	Device  string   `json:"device,omitempty"`
+}
+
+// parsePushoverError turns error response into *ProviderError by the invalid parameter
+func parsePushoverError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	ppr := &pushoverPushResponse{}
+	_ = json.Unmarshal(statusErr.Body, ppr)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch {
+	case ppr.Token == "invalid":
+		kind = ErrAuthFailed
+	case ppr.User == "invalid" || ppr.Device == "invalid":
+		kind = ErrInvalidDeviceKey
+	}
+	return &ProviderError{
+		Provider: Pushover,
+		Kind:     kind,
+		Code:     statusErr.StatusCode,
+		Message:  fmt.Sprintf("%s, Request: %s", strings.Join(ppr.Errors, "; "), ppr.Request),
+		Err:      err,
+	}
+}
+
+type pushoverPushProviderExtraParams struct {
+	AppToken string `json:"appToken" validate:"required,len=30,alphanum"`
+	// UserKey is a user key or a delivery group key
+	UserKey string `json:"userKey" validate:"required,len=30,alphanum"`
+	// Devices limits delivery to these devices of the user, all devices if empty
+	Devices []string `json:"devices,omitempty" validate:"omitempty,dive,max=25"`
+	// PriorityMap overrides defaultPushoverPriority
+	PriorityMap map[Severity]int `json:"priorityMap,omitempty" validate:"omitempty,dive,keys,oneof=info warning error critical,endkeys,gte=-2,lte=2"`
+	// Retry and Expire in seconds apply to emergency priority
+	Retry    int    `json:"retry,omitempty" validate:"omitempty,gte=30"`
+	Expire   int    `json:"expire,omitempty" validate:"omitempty,gt=0,lte=10800"`
+	URL      string `json:"url,omitempty" validate:"omitempty,url"`
+	URLTitle string `json:"urlTitle,omitempty" validate:"omitempty,max=100"`
+	Sound    string `json:"sound,omitempty" validate:"omitempty"`
+}
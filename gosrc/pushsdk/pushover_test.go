@@ -0,0 +1,161 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	testPushoverToken = "azGDORePK8gMaC0QOYAMyEEuzJnyUi"
+	testPushoverUser  = "uQiRzpo4DXghDmr9QzzfQu27cmVRsG"
+)
+
+func newTestPushover(t *testing.T, url string, extParams string) *pushoverPushProvider {
+	t.Helper()
+	prv := &pushoverPushProvider{}
+	err := json.Unmarshal([]byte(`{"serverURL": "`+url+`", "extParams": {"appToken": "`+testPushoverToken+`", "userKey": "`+testPushoverUser+`"`+extParams+`}}`), prv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prv.ProviderCommon = noRetry()
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	return prv
+}
+
+func TestPushoverRequest(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"status":1,"request":"5042853c-402d-4a18-abcb-168734a801de","receipt":"rLqVuqTRh62UzxtmqiaLzQmVcPgiCy"}`))
+	}))
+	defer srv.Close()
+	occurredAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		extParams string
+		severity  Severity
+		want      map[string]any
+	}{
+		{"default priority", ``, SeverityInfo, map[string]any{"priority": 0.0}},
+		{"high priority", ``, SeverityWarning, map[string]any{"priority": 1.0}},
+		{"emergency", ``, SeverityCritical, map[string]any{"priority": 2.0, "retry": 60.0, "expire": 3600.0}},
+		{"emergency with own retry", `, "retry": 30, "expire": 600`, SeverityCritical, map[string]any{"priority": 2.0, "retry": 30.0, "expire": 600.0}},
+		{"mapped to emergency", `, "priorityMap": {"warning": 2}`, SeverityWarning, map[string]any{"priority": 2.0, "retry": 60.0, "expire": 3600.0}},
+		{"devices", `, "devices": ["iphone", "desktop"], "sound": "siren"`, SeverityInfo, map[string]any{"priority": 0.0, "device": "iphone,desktop", "sound": "siren"}},
+	}
+	for _, c := range cases {
+		prv := newTestPushover(t, srv.URL, c.extParams)
+		spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{
+			Title:      "RDP Login - Success",
+			Severity:   c.severity,
+			OccurredAt: occurredAt,
+			Fields:     []PushField{{Name: FieldUser, Value: "alice"}, {Name: FieldSourceIP, Value: "10.0.0.8"}},
+		})
+		if err != nil {
+			t.Fatalf("%s: transform: %v", c.name, err)
+		}
+		resp, err := prv.SendPushContent(context.Background(), spc)
+		if err != nil {
+			t.Fatalf("%s: send: %v", c.name, err)
+		}
+		if !strings.Contains(resp.Message, "Receipt: rLqVuqTRh62UzxtmqiaLzQmVcPgiCy") {
+			t.Errorf("%s: push response %s", c.name, resp.Message)
+		}
+		want := map[string]any{
+			"token":     testPushoverToken,
+			"user":      testPushoverUser,
+			"title":     "RDP Login - Success",
+			"message":   "<b>User</b>: alice\n<b>Source IP</b>: 10.0.0.8",
+			"html":      1.0,
+			"timestamp": float64(occurredAt.Unix()),
+		}
+		for k, v := range c.want {
+			want[k] = v
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %v, want %v", c.name, k, got[k], v)
+			}
+		}
+		for k := range got {
+			if _, ok := want[k]; !ok {
+				t.Errorf("%s: unexpected field %s = %v", c.name, k, got[k])
+			}
+		}
+	}
+}
+
+func TestPushoverInvalidUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"user":"invalid","errors":["user identifier is not a valid user, group, or subscribed user key"],"status":0,"request":"5042853c"}`))
+	}))
+	defer srv.Close()
+	prv := newTestPushover(t, srv.URL, ``)
+	spc, _ := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice"})
+	_, err := prv.SendPushContent(context.Background(), spc)
+	if !errors.Is(err, ErrInvalidDeviceKey) {
+		t.Errorf("want ErrInvalidDeviceKey, got %v", err)
+	}
+}
+
+func TestPushoverVerifyConfig(t *testing.T) {
+	cases := []struct {
+		extParams string
+		valid     bool
+	}{
+		{`, "retry": 30, "expire": 10800`, true},
+		{`, "retry": 29`, false},
+		{`, "expire": 10801`, false},
+		{`, "priorityMap": {"critical": 3}`, false},
+		{`, "devices": ["a-device-name-longer-than-25"]`, false},
+	}
+	for _, c := range cases {
+		prv := &pushoverPushProvider{}
+		_ = json.Unmarshal([]byte(`{"extParams": {"appToken": "`+testPushoverToken+`", "userKey": "`+testPushoverUser+`"`+c.extParams+`}}`), prv)
+		if err := prv.VerifyConfig(); (err == nil) != c.valid {
+			t.Errorf("%s: verify config = %v, want valid %v", c.extParams, err, c.valid)
+		}
+	}
+}
+
+func TestPushoverMessageLimit(t *testing.T) {
+	prv := pushoverPushProvider{ExtraParams: &pushoverPushProviderExtraParams{}}
+	long := strings.Repeat("<&>", 400)
+	for _, g := range []*GeneralPushContent{
+		{Title: "RDP Login - Success", Description: long},
+		{Title: "RDP Login - Success", Fields: []PushField{{Name: FieldUser, Value: long}, {Name: FieldHost, Value: "HOST-01"}}},
+		{Title: "RDP Login - Success", Fields: []PushField{{Name: FieldUser, Value: "alice"}, {Name: FieldHost, Value: long}, {Name: FieldSourceIP, Value: "10.0.0.8"}}},
+	} {
+		spc, err := prv.TransformToSpecificPushContent(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := spc.(*pushoverPushContent).Message
+		if n := utf8.RuneCountInString(msg); n > 1024 {
+			t.Errorf("message has %d characters", n)
+		}
+		if !strings.HasSuffix(msg, "...") {
+			t.Errorf("truncated message should end with ellipsis: %q", msg[len(msg)-20:])
+		}
+		// every entity is complete, so unescaping gives back a prefix of raw text
+		last := strings.TrimSuffix(msg, "...")
+		if i := strings.LastIndex(last, "</b>: "); i >= 0 {
+			last = last[i+len("</b>: "):]
+		}
+		if raw := html.UnescapeString(last); !strings.HasPrefix(long, raw) || html.EscapeString(raw) != last {
+			t.Errorf("entity is cut: %q", last[len(last)-10:])
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pushsdk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrProviderAlreadyRegistered = errors.New("push provider already registered")
+	ErrProviderFactoryIsNil      = errors.New("push provider factory is nil")
+)
+
+// ProviderFactory returns a new, empty provider instance, config data will be unmarshalled into it
+type ProviderFactory func() PushProviderImpl
+
+var (
+	providerRegistry   = map[PushProvider]ProviderFactory{}
+	providerRegistryMu sync.RWMutex
+)
+
+// RegisterProvider makes a push provider available for PushConfig.PushMethods under name,
+// third-party packages should call it from their init()
+func RegisterProvider(name PushProvider, factory ProviderFactory) error {
+	if factory == nil {
+		return ErrProviderFactoryIsNil
+	}
+	providerRegistryMu.Lock()
+	defer providerRegistryMu.Unlock()
+	if _, exists := providerRegistry[name]; exists {
+		return fmt.Errorf("%w: %s", ErrProviderAlreadyRegistered, name)
+	}
+	providerRegistry[name] = factory
+	return nil
+}
+
+// MustRegisterProvider is like RegisterProvider but panics on failure
+func MustRegisterProvider(name PushProvider, factory ProviderFactory) {
+	err := RegisterProvider(name, factory)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisteredProviders returns all registered provider names in sorted order
+func RegisteredProviders() []PushProvider {
+	providerRegistryMu.RLock()
+	defer providerRegistryMu.RUnlock()
+	res := make([]PushProvider, 0, len(providerRegistry))
+	for k := range providerRegistry {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// newProviderInstance looks up the factory of name and instantiates an empty provider
+func newProviderInstance(name PushProvider) (PushProviderImpl, error) {
+	providerRegistryMu.RLock()
+	factory, exists := providerRegistry[name]
+	providerRegistryMu.RUnlock()
+	if !exists {
+		registered := RegisteredProviders()
+		names := make([]string, 0, len(registered))
+		for _, v := range registered {
+			names = append(names, string(v))
+		}
+		return nil, fmt.Errorf("%w: %q, registered providers: [%s]", ErrPushMethodNotSupported, name, strings.Join(names, ", "))
+	}
+	return factory(), nil
+}
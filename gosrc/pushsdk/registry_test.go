@@ -0,0 +1,73 @@
+package pushsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegisterProvider(t *testing.T) {
+	const name PushProvider = "registry-test"
+	t.Cleanup(func() {
+		providerRegistryMu.Lock()
+		delete(providerRegistry, name)
+		providerRegistryMu.Unlock()
+	})
+	if err := RegisterProvider(name, nil); !errors.Is(err, ErrProviderFactoryIsNil) {
+		t.Errorf("nil factory: got %v", err)
+	}
+	if err := RegisterProvider(name, func() PushProviderImpl { return &fakeProvider{} }); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if !slices.Contains(RegisteredProviders(), name) {
+		t.Errorf("registered providers %v miss %s", RegisteredProviders(), name)
+	}
+	if err := RegisterProvider(name, func() PushProviderImpl { return &fakeProvider{} }); !errors.Is(err, ErrProviderAlreadyRegistered) {
+		t.Errorf("duplicate registration: got %v", err)
+	}
+	// built-in ones are taken too
+	if err := RegisterProvider(Telegram, func() PushProviderImpl { return &fakeProvider{} }); !errors.Is(err, ErrProviderAlreadyRegistered) {
+		t.Errorf("registration over built-in provider: got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRegisterProvider should panic on duplicate registration")
+		}
+	}()
+	MustRegisterProvider(name, func() PushProviderImpl { return &fakeProvider{} })
+}
+
+func TestNewPusherProviders(t *testing.T) {
+	const name PushProvider = "registry-test-pusher"
+	MustRegisterProvider(name, func() PushProviderImpl { return &fakeProvider{} })
+	t.Cleanup(func() {
+		providerRegistryMu.Lock()
+		delete(providerRegistry, name)
+		providerRegistryMu.Unlock()
+	})
+	newPusher := func(methods string) (*pusher, error) {
+		conf := &PushConfig{}
+		if err := json.Unmarshal([]byte(`{"pushMethods": `+methods+`}`), conf); err != nil {
+			t.Fatal(err)
+		}
+		return NewPusher(conf)
+	}
+
+	p, err := newPusher(`{"registry-test-pusher": {}}`)
+	if err != nil {
+		t.Fatalf("new pusher with registered provider: %v", err)
+	}
+	if _, ok := p.providers[name].(*fakeProvider); !ok {
+		t.Errorf("providers = %v", p.providers)
+	}
+
+	_, err = newPusher(`{"registry-test-pusher": {}, "no-such-provider": {}}`)
+	if !errors.Is(err, ErrPushMethodNotSupported) {
+		t.Fatalf("unknown provider: got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"no-such-provider"`) || !strings.Contains(err.Error(), string(Telegram)) {
+		t.Errorf("error should name the unknown provider and list registered ones: %v", err)
+	}
+}
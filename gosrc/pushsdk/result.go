@@ -0,0 +1,81 @@
+package pushsdk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PushResult records the delivery outcome of a single provider,
+// Response may be kept on failure when the service replied with its own error code
+type PushResult struct {
+	Provider PushProvider
+	Response *PushResponse
+	Latency  time.Duration
+	Err      error
+}
+
+func (r *PushResult) Success() bool {
+	return r.Err == nil
+}
+
+func (r *PushResult) String() string {
+	if r.Success() {
+		if r.Response != nil {
+			return fmt.Sprintf("[%s] success in %s, %s", r.Provider, r.Latency, r.Response.String())
+		}
+		return fmt.Sprintf("[%s] success in %s", r.Provider, r.Latency)
+	}
+	return fmt.Sprintf("[%s] failed in %s: %s", r.Provider, r.Latency, r.Err.Error())
+}
+
+// PushReport combines results of all providers involved in one SendPush call
+type PushReport struct {
+	Results []*PushResult
+}
+
+func (r *PushReport) add(res *PushResult) {
+	r.Results = append(r.Results, res)
+	sort.Slice(r.Results, func(i, j int) bool { return r.Results[i].Provider < r.Results[j].Provider })
+}
+
+// Succeeded returns results of providers which delivered successfully
+func (r *PushReport) Succeeded() []*PushResult {
+	res := make([]*PushResult, 0, len(r.Results))
+	for _, v := range r.Results {
+		if v.Success() {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Failed returns results of providers which failed to deliver
+func (r *PushReport) Failed() []*PushResult {
+	res := make([]*PushResult, 0, len(r.Results))
+	for _, v := range r.Results {
+		if !v.Success() {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Err joins errors of all failed providers, nil if every provider succeeded
+func (r *PushReport) Err() error {
+	errs := make([]error, 0)
+	for _, v := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", v.Provider, v.Err))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *PushReport) String() string {
+	lines := make([]string, 0, len(r.Results))
+	for _, v := range r.Results {
+		lines = append(lines, v.String())
+	}
+	return fmt.Sprintf("%d/%d provider(s) succeeded; %s", len(r.Succeeded()), len(r.Results), strings.Join(lines, "; "))
+}
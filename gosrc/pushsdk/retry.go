@@ -0,0 +1,209 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/textproto"
+	"rdpalert/utils"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// network error classes that can be listed in RetryPolicy.RetryableNetErrors
+const (
+	NetErrTimeout = "timeout" // NetErrTimeout is any timeout reported by net.Error
+	NetErrDial    = "dial"    // NetErrDial is failure while connecting, e.g. connection refused
+	NetErrDNS     = "dns"     // NetErrDNS is failure while resolving host name
+	NetErrReset   = "reset"   // NetErrReset is connection reset or closed unexpectedly
+	NetErrAll     = "all"     // NetErrAll retries every network error
+)
+
+// DefaultRetryPolicy is applied when neither global nor provider retry policy is set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:          3,
+	BaseDelay:            ptrTo(Duration(time.Second)),
+	MaxDelay:             ptrTo(Duration(15 * time.Second)),
+	Jitter:               ptrTo(0.2),
+	RetryableStatusCodes: []int{408, 425, 429, 500, 502, 503, 504},
+	RetryableNetErrors:   []string{NetErrAll},
+}
+
+// RetryPolicy controls how a failed delivery is retried with exponential backoff
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt as well, 1 disables retry
+	MaxAttempts int `json:"maxAttempts,omitempty" validate:"omitempty,gte=1,lte=20"`
+	// BaseDelay, MaxDelay and Jitter are pointers as zero is a valid setting, nil means unset,
+	// zero BaseDelay retries immediately and zero MaxDelay doesn't cap the delay
+	BaseDelay *Duration `json:"baseDelay,omitempty" validate:"omitempty,gte=0"`
+	MaxDelay  *Duration `json:"maxDelay,omitempty" validate:"omitempty,gte=0"`
+	// Jitter is the ratio of random spread applied to each delay, from 0 to 1
+	Jitter               *float64 `json:"jitter,omitempty" validate:"omitempty,gte=0,lte=1"`
+	RetryableStatusCodes []int    `json:"retryableStatusCodes,omitempty" validate:"omitempty,dive,gte=100,lte=599"`
+	RetryableNetErrors   []string `json:"retryableNetErrors,omitempty" validate:"omitempty,dive,oneof=timeout dial dns reset all"`
+}
+
+// mergeRetryPolicy fills unset fields of override from base, any of them could be nil,
+// every field of returned policy is set
+func mergeRetryPolicy(base *RetryPolicy, override *RetryPolicy) *RetryPolicy {
+	res := DefaultRetryPolicy
+	for _, v := range []*RetryPolicy{base, override} {
+		if v == nil {
+			continue
+		}
+		if v.MaxAttempts != 0 {
+			res.MaxAttempts = v.MaxAttempts
+		}
+		if v.BaseDelay != nil {
+			res.BaseDelay = v.BaseDelay
+		}
+		if v.MaxDelay != nil {
+			res.MaxDelay = v.MaxDelay
+		}
+		if v.Jitter != nil {
+			res.Jitter = v.Jitter
+		}
+		if v.RetryableStatusCodes != nil {
+			res.RetryableStatusCodes = v.RetryableStatusCodes
+		}
+		if v.RetryableNetErrors != nil {
+			res.RetryableNetErrors = v.RetryableNetErrors
+		}
+	}
+	return &res
+}
+
+// unretryableError marks a failure that must not be retried whatever the policy is,
+// e.g. the message may have reached server already and another attempt would duplicate it
+type unretryableError struct {
+	err error
+}
+
+func (e *unretryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unretryableError) Unwrap() error {
+	return e.err
+}
+
+// markUnretryable wraps err so that IsRetryable always reports false for it
+func markUnretryable(err error) error {
+	return &unretryableError{err: err}
+}
+
+// IsRetryable tells whether err is worth another attempt under this policy
+func (rp *RetryPolicy) IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var finalErr *unretryableError
+	if errors.As(err, &finalErr) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(rp.RetryableStatusCodes, statusErr.StatusCode)
+	}
+	// transient negative completion reply of SMTP
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	class := classifyNetError(err)
+	if class == "" {
+		return false
+	}
+	return slices.Contains(rp.RetryableNetErrors, NetErrAll) || slices.Contains(rp.RetryableNetErrors, class)
+}
+
+// classifyNetError returns one of NetErr* class, empty if err is not a network error
+func classifyNetError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return NetErrDNS
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return NetErrTimeout
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return NetErrReset
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		if opErr.Op == "dial" {
+			return NetErrDial
+		}
+		return NetErrReset
+	}
+	return ""
+}
+
+// backoff calculates delay before the next attempt, attempt starts from 1
+func (rp *RetryPolicy) backoff(attempt int, lastErr error) time.Duration {
+	delay := float64(valueOrZero(rp.BaseDelay)) * math.Pow(2, float64(attempt-1))
+	if jitter := valueOrZero(rp.Jitter); jitter > 0 {
+		delay = delay * (1 - jitter + 2*jitter*rand.Float64())
+	}
+	// server told us when to come back
+	var statusErr *HTTPStatusError
+	if errors.As(lastErr, &statusErr) && float64(statusErr.RetryAfter) > delay {
+		delay = float64(statusErr.RetryAfter)
+	}
+	if maxDelay := valueOrZero(rp.MaxDelay); maxDelay > 0 && delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+	return time.Duration(delay)
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, attempts are used up or ctx is done,
+// every attempt is logged with name to tell providers apart
+func (rp *RetryPolicy) Do(ctx context.Context, name string, fn func(attempt int) error) error {
+	gLogger, err := utils.GetLoggerInstance()
+	if err != nil {
+		return err
+	}
+	maxAttempts := max(rp.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err = fn(attempt)
+		if err == nil {
+			if attempt > 1 {
+				gLogger.Info(fmt.Sprintf("%s: attempt %d/%d succeeded", name, attempt, maxAttempts))
+			}
+			return nil
+		}
+		if attempt >= maxAttempts || ctx.Err() != nil || !rp.IsRetryable(err) {
+			gLogger.Error(fmt.Sprintf("%s: attempt %d/%d failed, giving up: %s", name, attempt, maxAttempts, err.Error()))
+			return err
+		}
+		delay := rp.backoff(attempt, err)
+		gLogger.Warn(fmt.Sprintf("%s: attempt %d/%d failed, retry in %s: %s", name, attempt, maxAttempts, delay, err.Error()))
+		select {
+		case <-ctx.Done():
+			gLogger.Error(fmt.Sprintf("%s: gave up waiting for attempt %d/%d: %s", name, attempt+1, maxAttempts, ctx.Err().Error()))
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// ptrTo returns pointer to a copy of v, for optional settings
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+// valueOrZero dereferences p, nil gives zero value
+func valueOrZero[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
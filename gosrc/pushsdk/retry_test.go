@@ -0,0 +1,111 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	rp := &RetryPolicy{
+		BaseDelay: ptrTo(Duration(100 * time.Millisecond)),
+		MaxDelay:  ptrTo(Duration(time.Second)),
+		Jitter:    ptrTo(0.0),
+	}
+	for i, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		if got := rp.backoff(i+1, nil); got != want*time.Millisecond {
+			t.Errorf("attempt %d: delay %s, want %s", i+1, got, want*time.Millisecond)
+		}
+	}
+
+	cases := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"longer Retry-After wins", 1, 700 * time.Millisecond, 700 * time.Millisecond},
+		{"shorter Retry-After is ignored", 3, 100 * time.Millisecond, 400 * time.Millisecond},
+		{"Retry-After is capped too", 1, time.Minute, time.Second},
+	}
+	for _, c := range cases {
+		err := &HTTPStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: c.retryAfter}
+		if got := rp.backoff(c.attempt, err); got != c.want {
+			t.Errorf("%s: delay %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	// zero MaxDelay doesn't cap
+	rp.MaxDelay = ptrTo(Duration(0))
+	if got := rp.backoff(8, nil); got != 12800*time.Millisecond {
+		t.Errorf("uncapped delay %s", got)
+	}
+	// jitter spreads delay around the exponential one
+	rp.Jitter = ptrTo(0.5)
+	for i := 0; i < 100; i++ {
+		if got := rp.backoff(2, nil); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("jittered delay %s out of range", got)
+		}
+	}
+}
+
+func TestRetryDo(t *testing.T) {
+	errUnavailable := &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+	errBadRequest := &HTTPStatusError{StatusCode: http.StatusBadRequest}
+	errConfig := errors.New("bad config")
+	cases := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", []error{nil}, 1, nil},
+		{"success after retries", []error{errUnavailable, errUnavailable, nil}, 3, nil},
+		{"attempts used up", []error{errUnavailable, errUnavailable, errUnavailable, nil}, 3, errUnavailable},
+		{"non-retryable status", []error{errBadRequest, nil}, 1, errBadRequest},
+		{"marked unretryable", []error{markUnretryable(errUnavailable), nil}, 1, errUnavailable},
+		{"not a network error", []error{errConfig, nil}, 1, errConfig},
+	}
+	rp := mergeRetryPolicy(nil, &RetryPolicy{MaxAttempts: 3, BaseDelay: ptrTo(Duration(10 * time.Millisecond)), Jitter: ptrTo(0.0)})
+	for _, c := range cases {
+		calls := 0
+		var attempts []time.Time
+		err := rp.Do(context.Background(), "test", func(attempt int) error {
+			if attempt != calls+1 {
+				t.Errorf("%s: attempt %d after %d calls", c.name, attempt, calls)
+			}
+			attempts = append(attempts, time.Now())
+			calls++
+			return c.errs[calls-1]
+		})
+		if calls != c.wantCalls {
+			t.Errorf("%s: calls %d, want %d", c.name, calls, c.wantCalls)
+		}
+		if (err == nil) != (c.wantErr == nil) || !errors.Is(err, c.wantErr) {
+			t.Errorf("%s: error %v", c.name, err)
+		}
+		// delays before attempt 2 and 3 grow from base delay
+		for i := 1; i < len(attempts); i++ {
+			if gap, want := attempts[i].Sub(attempts[i-1]), rp.backoff(i, nil); gap < want {
+				t.Errorf("%s: attempt %d came %s after the previous one, want at least %s", c.name, i+1, gap, want)
+			}
+		}
+	}
+}
+
+func TestRetryDoStopsOnContext(t *testing.T) {
+	rp := &RetryPolicy{MaxAttempts: 5, BaseDelay: ptrTo(Duration(time.Minute)), RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls := 0
+	startT := time.Now()
+	err := rp.Do(ctx, "test", func(int) error {
+		calls++
+		return &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}
+	})
+	if calls != 1 || !errors.Is(err, context.DeadlineExceeded) || time.Since(startT) > 5*time.Second {
+		t.Errorf("calls %d, error %v after %s", calls, err, time.Since(startT))
+	}
+}
@@ -1,6 +1,7 @@
 package pushsdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -51,7 +52,12 @@ func (sc3p *sc3PushContent) AcceptExtParamSettings(d any) {
 	return
 }
 
+func init() {
+	MustRegisterProvider(ServChan3, func() PushProviderImpl { return &sc3PushProvider{} })
+}
+
 type sc3PushProvider struct {
+	ProviderCommon
 	// ServChan 3 for mobile universal
 	ProviderServerURL string                     `json:"serverURL" validate:"url,required"`
 	ExtraParams       *sc3PushProviderExtraParam `json:"extParams" validate:"omitempty"`
@@ -76,13 +82,13 @@ func (s sc3PushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (
 	return sc3p.FromGeneral(g)
 }
 
-func (s sc3PushProvider) SendPushContent(p PushContent) (*PushResponse, error) {
+func (s sc3PushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
 	pData := p.(*sc3PushContent)
 	body, err := pData.ToBytes()
 	if err != nil {
 		return nil, err
 	}
-	respData, _, err := SendHttpPostJSON(s.ProviderServerURL, body)
+	respData, _, err := s.PostJSON(ctx, ServChan3, s.ProviderServerURL, body)
 	if err != nil {
 		return nil, err
 	}
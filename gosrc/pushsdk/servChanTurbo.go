@@ -0,0 +1,201 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSCTSendKeyIsNotSet = errors.New("sct send key or server url is not set")
+
+	// sctpSendKeyRegex extracts user number of ServChan3 style key, which is served by its own host
+	sctpSendKeyRegex = regexp.MustCompile(`^sctp(\d+)t`)
+
+	// sctCodeKinds classifies code of push response, 40001 is "bad pushtoken", i.e. send key is wrong
+	sctCodeKinds = map[int]error{
+		40001: ErrAuthFailed,
+	}
+)
+
+// sctPushContent is an instance of https://sct.ftqq.com/sendkey, Channel and OpenID are "|" and "," joined
+type sctPushContent struct {
+	Title         string `json:"title" validate:"required,max=32"`
+	Description   string `json:"desp,omitempty" validate:"max=32768"`
+	ShortBriefing string `json:"short,omitempty" validate:"max=64"`
+	NoIP          int    `json:"noip,omitempty"`
+	Channel       string `json:"channel,omitempty"`
+	OpenID        string `json:"openid,omitempty"`
+	TagsStr       string `json:"tags,omitempty"`
+
+	providerName PushProvider
+}
+
+func (sctp *sctPushContent) Provider() PushProvider {
+	return sctp.providerName
+}
+
+func (sctp *sctPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	sctp.Title = truncateString(g.Title, 32)
+	sctp.ShortBriefing = truncateString(g.ShortTitle, 64)
+	sctp.Description = g.Description
+	return sctp, verifier.Struct(sctp)
+}
+
+func (sctp *sctPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(sctp)
+}
+
+func (sctp *sctPushContent) SetPushProvider() {
+	sctp.providerName = ServChanTurbo
+}
+
+func (sctp *sctPushContent) Init() {
+	sctp.SetPushProvider()
+}
+
+func (sctp *sctPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*sctPushProviderExtraParam)
+	if d1 == nil {
+		return
+	}
+	if len(d1.Channels) != 0 {
+		chans := make([]string, 0, len(d1.Channels))
+		for _, v := range d1.Channels {
+			chans = append(chans, strconv.Itoa(v))
+		}
+		sctp.Channel = strings.Join(chans, "|")
+	}
+	sctp.OpenID = strings.Join(d1.OpenIDs, ",")
+	if d1.HideCallerIP {
+		sctp.NoIP = 1
+	}
+	// kept for configs copied from sc3
+	if len(d1.CustomPushTags) != 0 {
+		sctp.TagsStr = strings.Join(d1.CustomPushTags, "|")
+	}
+}
+
+func init() {
+	MustRegisterProvider(ServChanTurbo, func() PushProviderImpl { return &sctPushProvider{} })
+}
+
+type sctPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is the full send URL as sc3 does, e.g. https://sctapi.ftqq.com/SCTxxx.send,
+	// it's built from extParams.sendKey if empty
+	ProviderServerURL string                     `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *sctPushProviderExtraParam `json:"extParams" validate:"omitempty"`
+}
+
+func (s sctPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(s)
+	if err1 != nil {
+		return err1
+	}
+	if s.ExtraParams != nil {
+		err2 := verifier.Struct(s.ExtraParams)
+		if err2 != nil {
+			return err2
+		}
+	}
+	if s.ProviderServerURL == "" && (s.ExtraParams == nil || s.ExtraParams.SendKey == "") {
+		return ErrSCTSendKeyIsNotSet
+	}
+	return nil
+}
+
+func (s sctPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	sctp := &sctPushContent{}
+	sctp.Init()
+	sctp.AcceptExtParamSettings(s.ExtraParams)
+	return sctp.FromGeneral(g)
+}
+
+// sendURL prefers serverURL, otherwise builds it from send key
+func (s sctPushProvider) sendURL() string {
+	if s.ProviderServerURL != "" {
+		return s.ProviderServerURL
+	}
+	return sctSendURL(s.ExtraParams.SendKey)
+}
+
+// sctSendURL builds send URL from key, sctp keys are routed to per-user host,
+// check: https://github.com/easychen/serverchan-sdk
+func sctSendURL(sendKey string) string {
+	if m := sctpSendKeyRegex.FindStringSubmatch(sendKey); m != nil {
+		return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", m[1], sendKey)
+	}
+	return fmt.Sprintf("https://sctapi.ftqq.com/%s.send", sendKey)
+}
+
+func (s sctPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*sctPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if s.ExtraParams != nil && s.ExtraParams.SendKey != "" {
+		secrets = append(secrets, s.ExtraParams.SendKey)
+	}
+	respData, _, err := s.SendRequest(ctx, ServChanTurbo, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     s.sendURL(),
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseStatusError(ServChanTurbo, err)
+	}
+	sctpr := &sctPushResponse{}
+	err = json.Unmarshal(respData, sctpr)
+	if err != nil {
+		return nil, err
+	}
+	return sctpr.ToGeneralPushResponse()
+}
+
+// sctPushResponse differs from sc3PushResponse, pushid is a string and errno is inside data
+type sctPushResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		PushID  string `json:"pushid"`
+		ReadKey string `json:"readkey"`
+		Error   string `json:"error"`
+		ErrorNo int    `json:"errno"`
+	} `json:"data"`
+}
+
+func (sctpr *sctPushResponse) ToGeneralPushResponse() (*PushResponse, error) {
+	respMsg := fmt.Sprintf("ErrorNo: %d , PushID: %s, ReadKey: %s, OriRespMsg: %s, Error: %s", sctpr.Data.ErrorNo, sctpr.Data.PushID,
+		sctpr.Data.ReadKey, sctpr.Message, sctpr.Data.Error)
+	gpr := &PushResponse{
+		Code:      sctpr.Code,
+		Message:   respMsg,
+		Timestamp: time.Now().Unix(),
+	}
+	if sctpr.Code != 0 {
+		return gpr, newCodeError(ServChanTurbo, sctpr.Code, sctpr.Message, sctCodeKinds)
+	}
+	return gpr, nil
+}
+
+type sctPushProviderExtraParam struct {
+	SendKey string `json:"sendKey,omitempty" validate:"omitempty,startswith=SCT|startswith=sctp"`
+	// Channels selects message channels, e.g. 9 for service account and 66 for WeCom app, check: https://sct.ftqq.com/sendkey
+	Channels []int `json:"channels,omitempty" validate:"omitempty,max=2,dive,gte=0"`
+	// OpenIDs are CC list of test account or WeCom app channel
+	OpenIDs      []string `json:"openIDs,omitempty" validate:"omitempty,dive,required"`
+	HideCallerIP bool     `json:"hideCallerIP,omitempty"`
+	// CustomPushTags is accepted for configs copied from sc3
+	CustomPushTags []string `json:"customPushTags" validate:"omitempty"`
+}
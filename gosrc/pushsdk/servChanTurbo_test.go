@@ -0,0 +1,117 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSCTTitleTruncatedByCharacter(t *testing.T) {
+	sctp := &sctPushContent{}
+	sctp.Init()
+	_, err := sctp.FromGeneral(&GeneralPushContent{
+		Title:      "RDP 登录成功 - " + strings.Repeat("主机", 20),
+		ShortTitle: strings.Repeat("用户", 40),
+	})
+	if err != nil {
+		t.Fatalf("from general: %v", err)
+	}
+	if !utf8.ValidString(sctp.Title) || utf8.RuneCountInString(sctp.Title) != 32 {
+		t.Errorf("title = %q, want 32 valid characters", sctp.Title)
+	}
+	if !utf8.ValidString(sctp.ShortBriefing) || utf8.RuneCountInString(sctp.ShortBriefing) != 64 {
+		t.Errorf("short = %q, want 64 valid characters", sctp.ShortBriefing)
+	}
+}
+
+func TestSCTRequest(t *testing.T) {
+	var (
+		path string
+		got  map[string]any
+	)
+	reply := `{"code":0,"message":"","data":{"pushid":"134","readkey":"SCTxxx","error":"SUCCESS","errno":0}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer srv.Close()
+	send := func(extParams *sctPushProviderExtraParam) (*PushResponse, error) {
+		prv := sctPushProvider{ProviderCommon: noRetry(), ProviderServerURL: srv.URL + "/SCTabc.send", ExtraParams: extParams}
+		if err := prv.VerifyConfig(); err != nil {
+			t.Fatalf("verify config: %v", err)
+		}
+		spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", ShortTitle: "alice", Description: "From: 10.0.0.8"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return prv.SendPushContent(context.Background(), spc)
+	}
+
+	resp, err := send(&sctPushProviderExtraParam{Channels: []int{9, 66}, OpenIDs: []string{"openid1", "openid2"}, HideCallerIP: true})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if path != "/SCTabc.send" || !strings.Contains(resp.Message, "PushID: 134") {
+		t.Errorf("path %s, push response %s", path, resp.Message)
+	}
+	want := map[string]any{"title": "RDP Login - Success", "short": "alice", "desp": "From: 10.0.0.8", "channel": "9|66", "openid": "openid1,openid2", "noip": 1.0}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+	if _, ok := got["tags"]; ok {
+		t.Errorf("tags sent without customPushTags")
+	}
+
+	// optional fields are left out
+	if _, err = send(&sctPushProviderExtraParam{}); err != nil {
+		t.Fatalf("send without options: %v", err)
+	}
+	for _, k := range []string{"channel", "openid", "noip"} {
+		if _, ok := got[k]; ok {
+			t.Errorf("%s sent without setting: %v", k, got[k])
+		}
+	}
+
+	// service error comes with HTTP 200
+	reply = `{"code":40001,"message":"bad pushtoken","data":null}`
+	resp, err = send(nil)
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) || provErr.Provider != ServChanTurbo || provErr.Code != 40001 || !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("want ErrAuthFailed of code 40001, got %v", err)
+	}
+	if resp == nil || resp.Code != 40001 {
+		t.Errorf("push response = %v, want the replied one", resp)
+	}
+	reply = `{"code":20001,"message":"超过当天的发送次数限制","data":null}`
+	if _, err = send(nil); !errors.As(err, &provErr) || provErr.Code != 20001 || !errors.Is(err, ErrHttpRequestFailed) {
+		t.Errorf("unknown code: got %v", err)
+	}
+}
+
+func TestSCTVerifyChannels(t *testing.T) {
+	cases := []struct {
+		channels []int
+		valid    bool
+	}{
+		{nil, true},
+		{[]int{9}, true},
+		{[]int{9, 66}, true},
+		{[]int{9, 66, 18}, false},
+		{[]int{-1}, false},
+	}
+	for _, c := range cases {
+		prv := sctPushProvider{ExtraParams: &sctPushProviderExtraParam{SendKey: "SCTabc", Channels: c.channels}}
+		if err := prv.VerifyConfig(); (err == nil) != c.valid {
+			t.Errorf("channels %v: verify config = %v, want valid %v", c.channels, err, c.valid)
+		}
+	}
+}
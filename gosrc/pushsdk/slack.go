@@ -0,0 +1,212 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// slackTextEscaper escapes control characters of mrkdwn, check: https://api.slack.com/reference/surfaces/formatting#escaping
+var slackTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackPushContent is an incoming webhook message, blocks are wrapped in attachment to show severity color
+type slackPushContent struct {
+	Text        string            `json:"text" validate:"required"`
+	Username    string            `json:"username,omitempty"`
+	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	Channel     string            `json:"channel,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+
+	// provider info
+	providerName PushProvider
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock covers header, section and context blocks of Block Kit
+type slackBlock struct {
+	Type     string            `json:"type"`
+	Text     *slackTextObject  `json:"text,omitempty"`
+	Fields   []slackTextObject `json:"fields,omitempty"`
+	Elements []slackTextObject `json:"elements,omitempty"`
+}
+
+type slackTextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (spc *slackPushContent) Init() {
+	spc.SetPushProvider()
+}
+
+func (spc *slackPushContent) Provider() PushProvider {
+	return spc.providerName
+}
+
+func (spc *slackPushContent) SetPushProvider() {
+	spc.providerName = Slack
+}
+
+func (spc *slackPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*slackPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	spc.Username = d1.Username
+	spc.IconEmoji = d1.IconEmoji
+	spc.Channel = d1.Channel
+}
+
+// FromGeneral builds header, fields and footer blocks, structured fields are preferred over Description
+func (spc *slackPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	spc.Text = slackTextEscaper.Replace(g.Title)
+	if g.ShortTitle != "" {
+		spc.Text = slackTextEscaper.Replace(g.Title + ": " + g.ShortTitle)
+	}
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackTextObject{Type: "plain_text", Text: truncateString(g.Title, 150)}},
+	}
+	// slack rejects empty text object with invalid_blocks, so empty text is left out
+	if len(g.Fields) == 0 {
+		if strings.TrimSpace(g.Description) != "" {
+			blocks = append(blocks, slackBlock{
+				Type: "section",
+				Text: &slackTextObject{Type: "mrkdwn", Text: slackTextEscaper.Replace(g.Description)},
+			})
+		}
+	} else {
+		section := slackBlock{Type: "section"}
+		if strings.TrimSpace(g.ShortTitle) != "" {
+			section.Text = &slackTextObject{Type: "mrkdwn", Text: slackTextEscaper.Replace(g.ShortTitle)}
+		}
+		for _, v := range g.Fields {
+			// section block holds at most 10 fields
+			if len(section.Fields) == 10 {
+				break
+			}
+			section.Fields = append(section.Fields, slackTextObject{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*%s*\n%s", slackTextEscaper.Replace(v.Name), slackTextEscaper.Replace(v.Value)),
+			})
+		}
+		blocks = append(blocks, section)
+	}
+	if host := g.Field(FieldHost); host != "" {
+		blocks = append(blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackTextObject{{Type: "mrkdwn", Text: slackTextEscaper.Replace(host)}},
+		})
+	}
+	spc.Attachments = []slackAttachment{{
+		Color:  fmt.Sprintf("#%06X", g.SeverityOrDefault().Color()),
+		Blocks: blocks,
+	}}
+	return spc, nil
+}
+
+func (spc *slackPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(spc)
+}
+
+func init() {
+	MustRegisterProvider(Slack, func() PushProviderImpl { return &slackPushProvider{} })
+}
+
+type slackPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is the incoming webhook URL, https://hooks.slack.com/services/...
+	ProviderServerURL string                        `json:"serverURL" validate:"url,required"`
+	ExtraParams       *slackPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (s slackPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(s)
+	if err1 != nil {
+		return err1
+	}
+	if s.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(s.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (s slackPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	spc := &slackPushContent{}
+	spc.Init()
+	spc.AcceptExtParamSettings(s.ExtraParams)
+	return spc.FromGeneral(g)
+}
+
+func (s slackPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*slackPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if u, err := url.Parse(s.ProviderServerURL); err == nil {
+		secrets = append(secrets, u.Path)
+	}
+	respData, statusCode, err := s.SendRequest(ctx, Slack, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     s.ProviderServerURL,
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseSlackError(err)
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   string(respData),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// parseSlackError turns error response into *ProviderError, slack replies a plain text error code like "invalid_payload",
+// check: https://api.slack.com/messaging/webhooks#handling_errors
+func parseSlackError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	errCode := strings.TrimSpace(string(statusErr.Body))
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch errCode {
+	case "invalid_token", "team_disabled":
+		kind = ErrAuthFailed
+	case "no_service", "no_service_id", "channel_not_found", "channel_is_archived":
+		kind = ErrInvalidDeviceKey
+	}
+	if statusErr.StatusCode == http.StatusTooManyRequests {
+		errCode = fmt.Sprintf("%s retry after %s", errCode, statusErr.RetryAfter)
+	}
+	return &ProviderError{
+		Provider: Slack,
+		Kind:     kind,
+		Code:     statusErr.StatusCode,
+		Message:  strings.TrimSpace(errCode),
+		Err:      err,
+	}
+}
+
+type slackPushProviderExtraParams struct {
+	// Username, IconEmoji and Channel only take effect for legacy webhooks
+	Username  string `json:"username,omitempty" validate:"omitempty"`
+	IconEmoji string `json:"iconEmoji,omitempty" validate:"omitempty"`
+	Channel   string `json:"channel,omitempty" validate:"omitempty"`
+}
@@ -0,0 +1,50 @@
+package pushsdk
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlackNoEmptyText(t *testing.T) {
+	cases := []struct {
+		name     string
+		g        *GeneralPushContent
+		text     string
+		sections int
+	}{
+		{"fields without short title", &GeneralPushContent{Title: "RDP Login", Fields: []PushField{{Name: FieldUser, Value: "alice"}}}, "RDP Login", 1},
+		{"fields with short title", &GeneralPushContent{Title: "RDP Login", ShortTitle: "alice from <10.0.0.8>", Fields: []PushField{{Name: FieldUser, Value: "alice"}}}, "RDP Login: alice from &lt;10.0.0.8&gt;", 1},
+		{"description only", &GeneralPushContent{Title: "RDP Login", Description: "alice from 10.0.0.8"}, "RDP Login", 1},
+		{"title only", &GeneralPushContent{Title: "RDP Login", Description: " \n"}, "RDP Login", 0},
+	}
+	for _, c := range cases {
+		spc, err := slackPushProvider{}.TransformToSpecificPushContent(c.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := spc.(*slackPushContent)
+		if msg.Text != c.text {
+			t.Errorf("%s: text = %q, want %q", c.name, msg.Text, c.text)
+		}
+		sections := 0
+		for _, b := range msg.Attachments[0].Blocks {
+			if b.Type == "section" {
+				sections++
+				if b.Text == nil && len(b.Fields) == 0 {
+					t.Errorf("%s: section has neither text nor fields", c.name)
+				}
+			}
+			for _, v := range append(append([]slackTextObject{}, b.Fields...), b.Elements...) {
+				if strings.TrimSpace(v.Text) == "" {
+					t.Errorf("%s: empty text object in %s block", c.name, b.Type)
+				}
+			}
+			if b.Text != nil && strings.TrimSpace(b.Text.Text) == "" {
+				t.Errorf("%s: empty text of %s block", c.name, b.Type)
+			}
+		}
+		if sections != c.sections {
+			t.Errorf("%s: %d section(s), want %d", c.name, sections, c.sections)
+		}
+	}
+}
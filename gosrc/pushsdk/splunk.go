@@ -0,0 +1,182 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultSplunkSourceType = "rdpalert:login"
+
+// splunkPushContent is a single event of HTTP Event Collector, check:
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+type splunkPushContent struct {
+	// Time is epoch seconds with milliseconds as fraction
+	Time       float64     `json:"time"`
+	Host       string      `json:"host,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	SourceType string      `json:"sourcetype,omitempty"`
+	Index      string      `json:"index,omitempty"`
+	Event      *alertEvent `json:"event" validate:"required"`
+
+	// provider info
+	providerName PushProvider
+}
+
+func (spc *splunkPushContent) Init() {
+	spc.SetPushProvider()
+	spc.Source = "rdpalert"
+	spc.SourceType = defaultSplunkSourceType
+}
+
+func (spc *splunkPushContent) Provider() PushProvider {
+	return spc.providerName
+}
+
+func (spc *splunkPushContent) SetPushProvider() {
+	spc.providerName = Splunk
+}
+
+func (spc *splunkPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*splunkPushProviderExtraParams)
+	spc.Index = d1.Index
+	if d1.Source != "" {
+		spc.Source = d1.Source
+	}
+	if d1.SourceType != "" {
+		spc.SourceType = d1.SourceType
+	}
+}
+
+func (spc *splunkPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	spc.Event = newAlertEvent(g)
+	spc.Time = float64(spc.Event.Timestamp.UnixMilli()) / 1000
+	spc.Host = spc.Event.Host
+	return spc, nil
+}
+
+func (spc *splunkPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(spc)
+}
+
+func init() {
+	MustRegisterProvider(Splunk, func() PushProviderImpl { return &splunkPushProvider{} })
+}
+
+type splunkPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is the event endpoint, https://splunk.corp.local:8088/services/collector/event
+	ProviderServerURL string                         `json:"serverURL" validate:"url,required"`
+	ExtraParams       *splunkPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (s splunkPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(s)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(s.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (s splunkPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	spc := &splunkPushContent{}
+	spc.Init()
+	spc.AcceptExtParamSettings(s.ExtraParams)
+	return spc.FromGeneral(g)
+}
+
+func (s splunkPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*splunkPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{
+		"Content-Type":  {postJSONContentType},
+		"Authorization": {"Splunk " + s.ExtraParams.Token},
+	}
+	// indexer acknowledgement requires a channel, ackId is only replied when it's enabled on token
+	if s.ExtraParams.Channel != "" {
+		header.Set("X-Splunk-Request-Channel", s.ExtraParams.Channel)
+	}
+	respData, statusCode, err := s.SendRequest(ctx, Splunk, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     s.ProviderServerURL,
+		Header:  header,
+		Body:    body,
+		Secrets: []string{s.ExtraParams.Token},
+	})
+	if err != nil {
+		return nil, parseSplunkError(err)
+	}
+	spr := &splunkPushResponse{}
+	err = json.Unmarshal(respData, spr)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PushResponse{
+		Code:      statusCode,
+		Message:   spr.Text,
+		Timestamp: time.Now().Unix(),
+	}
+	if spr.AckID != nil {
+		resp.Ack = strconv.FormatInt(*spr.AckID, 10)
+	}
+	return resp, nil
+}
+
+// splunkPushResponse is replied for both success and failure, code 0 means success, check:
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/TroubleshootHTTPEventCollector#Possible_error_codes
+type splunkPushResponse struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId,omitempty"`
+}
+
+// parseSplunkError turns error response into *ProviderError, HEC code is preferred over HTTP status
+func parseSplunkError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	spr := &splunkPushResponse{Code: -1}
+	_ = json.Unmarshal(statusErr.Body, spr)
+	kind := errorKindByStatus(statusErr.StatusCode)
+	switch spr.Code {
+	case 1, 2, 3, 4:
+		// token disabled, token required, invalid authorization, invalid token
+		kind = ErrAuthFailed
+	case 8, 9:
+		// internal server error, server is busy
+		kind = ErrServerError
+	}
+	code := spr.Code
+	if code < 0 {
+		code = statusErr.StatusCode
+	}
+	return &ProviderError{
+		Provider: Splunk,
+		Kind:     kind,
+		Code:     code,
+		Message:  spr.Text,
+		Err:      err,
+	}
+}
+
+type splunkPushProviderExtraParams struct {
+	Token string `json:"token" validate:"required,uuid"`
+	// Index must be allowed by token, default index of token is used if empty
+	Index string `json:"index,omitempty" validate:"omitempty"`
+	// Source is rdpalert and SourceType is rdpalert:login by default
+	Source     string `json:"source,omitempty" validate:"omitempty"`
+	SourceType string `json:"sourceType,omitempty" validate:"omitempty"`
+	// Channel is a GUID sent in X-Splunk-Request-Channel, required if indexer acknowledgement is enabled
+	Channel string `json:"channel,omitempty" validate:"omitempty,uuid"`
+}
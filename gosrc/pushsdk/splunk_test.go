@@ -0,0 +1,99 @@
+package pushsdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	testSplunkToken   = "12345678-1234-1234-1234-123456789012"
+	testSplunkChannel = "0aecc2e6-77a5-4a3b-9c41-54d1f6f2c0a1"
+)
+
+func newSplunkServer(t *testing.T, status int, reply string, got *http.Header) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		*got = r.Header.Clone()
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sendSplunk(t *testing.T, serverURL string) (*PushResponse, error) {
+	t.Helper()
+	prv := &splunkPushProvider{
+		ProviderCommon:    noRetry(),
+		ProviderServerURL: serverURL + "/services/collector/event",
+		ExtraParams:       &splunkPushProviderExtraParams{Token: testSplunkToken, Channel: testSplunkChannel},
+	}
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(&GeneralPushContent{Title: "RDP Login - Success", EventType: EventRDPLoginSuccess})
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	return prv.SendPushContent(context.Background(), spc)
+}
+
+func TestSplunkAckID(t *testing.T) {
+	var got http.Header
+	srv := newSplunkServer(t, http.StatusOK, `{"text":"Success","code":0,"ackId":42}`, &got)
+	resp, err := sendSplunk(t, srv.URL)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "42" {
+		t.Errorf("ack = %q, want ackId 42", resp.Ack)
+	}
+	if v := got.Get("Authorization"); v != "Splunk "+testSplunkToken {
+		t.Errorf("Authorization = %q", v)
+	}
+	if v := got.Get("X-Splunk-Request-Channel"); v != testSplunkChannel {
+		t.Errorf("X-Splunk-Request-Channel = %q", v)
+	}
+
+	srv = newSplunkServer(t, http.StatusOK, `{"text":"Success","code":0}`, &got)
+	resp, err = sendSplunk(t, srv.URL)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if resp.Ack != "" {
+		t.Errorf("ack = %q, want empty without indexer acknowledgement", resp.Ack)
+	}
+}
+
+func TestSplunkError(t *testing.T) {
+	cases := []struct {
+		status int
+		reply  string
+		kind   error
+		code   int
+	}{
+		{http.StatusForbidden, `{"text":"Invalid token","code":4}`, ErrAuthFailed, 4},
+		{http.StatusBadRequest, `{"text":"Token disabled","code":1}`, ErrAuthFailed, 1},
+		{http.StatusServiceUnavailable, `{"text":"Server is busy","code":9}`, ErrServerError, 9},
+		{http.StatusBadRequest, `{"text":"Invalid data format","code":6}`, ErrHttpRequestFailed, 6},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, ErrServerError, http.StatusBadGateway},
+	}
+	for _, c := range cases {
+		var got http.Header
+		srv := newSplunkServer(t, c.status, c.reply, &got)
+		_, err := sendSplunk(t, srv.URL)
+		var pErr *ProviderError
+		if !errors.As(err, &pErr) {
+			t.Errorf("%s: error = %v, want *ProviderError", c.reply, err)
+			continue
+		}
+		if pErr.Kind != c.kind || pErr.Code != c.code {
+			t.Errorf("%s: got kind %v code %d, want %v code %d", c.reply, pErr.Kind, pErr.Code, c.kind, c.code)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package pushsdk
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"rdpalert/embedded"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SyslogPayloadText = "text"
+	SyslogPayloadCEF  = "cef"
+	SyslogPayloadLEEF = "leef"
+
+	defaultSyslogAppName = "RDPAlert"
+	// defaultSyslogSDID uses example enterprise number of RFC 5612, replace it with your own if you have one
+	defaultSyslogSDID = "rdplogin@32473"
+
+	syslogVendor  = "kmahyyg"
+	syslogProduct = "DumbRDPAlert"
+)
+
+var ErrSyslogSchemeNotSupported = errors.New("syslog server url scheme must be udp, tcp or tls")
+
+// syslogFacilities are facility codes of RFC 5424
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11, "ntp": 12, "security": 13, "console": 14, "clock": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities maps Severity to syslog severity, 2 is critical and 6 is informational
+var syslogSeverities = map[Severity]int{
+	SeverityInfo:     6,
+	SeverityWarning:  4,
+	SeverityError:    3,
+	SeverityCritical: 2,
+}
+
+// cefSeverities maps Severity to CEF and LEEF severity, from 0 to 10
+var cefSeverities = map[Severity]int{
+	SeverityInfo:     3,
+	SeverityWarning:  5,
+	SeverityError:    8,
+	SeverityCritical: 10,
+}
+
+var (
+	syslogSDParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	cefHeaderEscaper     = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtEscaper        = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	leefHeaderEscaper    = strings.NewReplacer(`|`, ` `)
+	leefValueEscaper     = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+// syslogPushContent is a single RFC 5424 message, ToBytes renders it without transport framing
+type syslogPushContent struct {
+	Facility  int
+	Severity  Severity
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	SDID      string
+	// SDParams are structured data params in order
+	SDParams [][2]string
+	Message  string
+
+	payload string
+	// provider info
+	providerName PushProvider
+}
+
+func (spc *syslogPushContent) Init() {
+	spc.Facility = syslogFacilities["authpriv"]
+	spc.AppName = defaultSyslogAppName
+	spc.ProcID = strconv.Itoa(os.Getpid())
+	spc.SDID = defaultSyslogSDID
+	spc.payload = SyslogPayloadText
+	spc.SetPushProvider()
+}
+
+func (spc *syslogPushContent) Provider() PushProvider {
+	return spc.providerName
+}
+
+func (spc *syslogPushContent) SetPushProvider() {
+	spc.providerName = Syslog
+}
+
+func (spc *syslogPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*syslogPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Facility != "" {
+		spc.Facility = syslogFacilities[d1.Facility]
+	}
+	if d1.AppName != "" {
+		spc.AppName = d1.AppName
+	}
+	if d1.SDID != "" {
+		spc.SDID = d1.SDID
+	}
+	if d1.Payload != "" {
+		spc.payload = d1.Payload
+	}
+	spc.Hostname = d1.Hostname
+}
+
+// FromGeneral puts login fields into structured data, message is plain text, CEF or LEEF
+func (spc *syslogPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	spc.Severity = g.SeverityOrDefault()
+	spc.Timestamp = g.OccurredAtOrNow()
+	if spc.Hostname == "" {
+		spc.Hostname = g.Field(FieldHost)
+	}
+	spc.MsgID = g.EventType
+	spc.SDParams = [][2]string{
+		{"user", g.Field(FieldUser)},
+		{"domain", g.Field(FieldDomain)},
+		{"srcIP", g.Field(FieldSourceIP)},
+		{"host", g.Field(FieldHost)},
+		{"hostIPs", g.Field(FieldHostIPs)},
+	}
+	switch spc.payload {
+	case SyslogPayloadCEF:
+		spc.Message = formatCEF(g)
+	case SyslogPayloadLEEF:
+		spc.Message = formatLEEF(g)
+	default:
+		spc.Message = g.Title + ": " + g.ShortTitle
+	}
+	return spc, nil
+}
+
+// ToBytes renders RFC 5424 message, nil values are written as "-" and MSG starts with BOM as it's UTF-8
+func (spc *syslogPushContent) ToBytes() ([]byte, error) {
+	sd := &strings.Builder{}
+	sd.WriteString("[" + spc.SDID)
+	for _, v := range spc.SDParams {
+		if v[1] == "" {
+			continue
+		}
+		sd.WriteString(" " + v[0] + `="` + syslogSDParamEscaper.Replace(v[1]) + `"`)
+	}
+	sd.WriteString("]")
+	msg := fmt.Sprintf("<%d>1 %s %s %s %s %s %s \ufeff%s",
+		spc.Facility*8+syslogSeverities[spc.Severity],
+		spc.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		syslogHeaderField(spc.Hostname, 255),
+		syslogHeaderField(spc.AppName, 48),
+		syslogHeaderField(spc.ProcID, 128),
+		syslogHeaderField(spc.MsgID, 32),
+		sd.String(),
+		spc.Message,
+	)
+	return []byte(msg), nil
+}
+
+// syslogHeaderField keeps printable US-ASCII only as header fields require
+func syslogHeaderField(s string, maxLen int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < maxLen; i++ {
+		if s[i] >= 33 && s[i] <= 126 {
+			b = append(b, s[i])
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// formatCEF renders ArcSight Common Event Format, login user is the destination user of the host logged into
+func formatCEF(g *GeneralPushContent) string {
+	ext := [][2]string{
+		{"rt", strconv.FormatInt(g.OccurredAtOrNow().UnixMilli(), 10)},
+		{"src", g.Field(FieldSourceIP)},
+		{"duser", g.Field(FieldUser)},
+		{"dntdom", g.Field(FieldDomain)},
+		{"dhost", g.Field(FieldHost)},
+		{"cs1", g.Field(FieldHostIPs)},
+		{"msg", g.ShortTitle},
+	}
+	if g.Field(FieldHostIPs) != "" {
+		ext = append(ext, [2]string{"cs1Label", "Host IPs"})
+	}
+	exts := make([]string, 0, len(ext))
+	for _, v := range ext {
+		if v[1] != "" {
+			exts = append(exts, v[0]+"="+cefExtEscaper.Replace(v[1]))
+		}
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s", syslogVendor, syslogProduct, cefHeaderEscaper.Replace(embedded.CurVersionStr),
+		cefHeaderEscaper.Replace(g.EventType), cefHeaderEscaper.Replace(g.Title), cefSeverities[g.SeverityOrDefault()], strings.Join(exts, " "))
+}
+
+// formatLEEF renders IBM QRadar LEEF 1.0, attributes are tab delimited
+func formatLEEF(g *GeneralPushContent) string {
+	attrs := [][2]string{
+		// devTime is in epoch milliseconds without devTimeFormat
+		{"devTime", strconv.FormatInt(g.OccurredAtOrNow().UnixMilli(), 10)},
+		{"sev", strconv.Itoa(cefSeverities[g.SeverityOrDefault()])},
+		{"src", g.Field(FieldSourceIP)},
+		{"usrName", g.Field(FieldUser)},
+		{"domain", g.Field(FieldDomain)},
+		{"identHostName", g.Field(FieldHost)},
+		{"hostIPs", g.Field(FieldHostIPs)},
+	}
+	kvs := make([]string, 0, len(attrs))
+	for _, v := range attrs {
+		if v[1] != "" {
+			kvs = append(kvs, v[0]+"="+leefValueEscaper.Replace(v[1]))
+		}
+	}
+	return fmt.Sprintf("LEEF:1.0|%s|%s|%s|%s|%s", syslogVendor, syslogProduct, leefHeaderEscaper.Replace(embedded.CurVersionStr),
+		leefHeaderEscaper.Replace(g.EventType), strings.Join(kvs, "\t"))
+}
+
+func init() {
+	MustRegisterProvider(Syslog, func() PushProviderImpl { return &syslogPushProvider{} })
+}
+
+type syslogPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is udp://host:514, tcp://host:514 or tls://host:6514, TLS setting is taken from httpClient
+	ProviderServerURL string                         `json:"serverURL" validate:"url,required"`
+	ExtraParams       *syslogPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (s syslogPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(s)
+	if err1 != nil {
+		return err1
+	}
+	serverURL, err := url.Parse(s.ProviderServerURL)
+	if err != nil {
+		return err
+	}
+	switch serverURL.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return fmt.Errorf("%w: %s", ErrSyslogSchemeNotSupported, serverURL.Scheme)
+	}
+	if s.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(s.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (s syslogPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	spc := &syslogPushContent{}
+	spc.Init()
+	spc.AcceptExtParamSettings(s.ExtraParams)
+	return spc.FromGeneral(g)
+}
+
+func (s syslogPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*syslogPushContent)
+	msg, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	policy := s.Retry
+	if policy == nil {
+		policy = mergeRetryPolicy(nil, nil)
+	}
+	err = policy.Do(ctx, string(Syslog), func(_ int) error {
+		return s.sendMessage(ctx, msg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PushResponse{
+		Code:      0,
+		Message:   fmt.Sprintf("Sent %d bytes to %s", len(msg), s.ProviderServerURL),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// sendMessage sends msg in a single datagram over UDP, or with octet counting framing of RFC 6587 over TCP and TLS,
+// a failed write is retried only if nothing could have reached collector
+func (s syslogPushProvider) sendMessage(ctx context.Context, msg []byte) error {
+	serverURL, err := url.Parse(s.ProviderServerURL)
+	if err != nil {
+		return err
+	}
+	port := serverURL.Port()
+	if port == "" {
+		port = "514"
+		if serverURL.Scheme == "tls" {
+			port = "6514"
+		}
+	}
+	addr := net.JoinHostPort(serverURL.Hostname(), port)
+	dialer := &net.Dialer{Timeout: s.HTTPClient.timeoutOrDefault()}
+	var conn net.Conn
+	switch serverURL.Scheme {
+	case "tls":
+		tlsConf, err := NewTLSConfig(s.HTTPClient)
+		if err != nil {
+			return err
+		}
+		tlsConf.ServerName = serverURL.Hostname()
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConf}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+	default:
+		conn, err = dialer.DialContext(ctx, serverURL.Scheme, addr)
+		if err != nil {
+			return err
+		}
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	_ = conn.SetDeadline(time.Now().Add(s.HTTPClient.timeoutOrDefault()))
+	if serverURL.Scheme != "udp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	n, err := conn.Write(msg)
+	return syslogWriteError(serverURL.Scheme, n, len(msg), err)
+}
+
+// syslogWriteError tells apart write failures that are safe to retry, a datagram is either sent or not,
+// while collector may have received part of a stream, TLS doesn't even report bytes that left in a broken record
+func syslogWriteError(scheme string, written int, total int, err error) error {
+	if err == nil || scheme == "udp" || (scheme == "tcp" && written == 0) {
+		return err
+	}
+	return markUnretryable(fmt.Errorf("syslog message may be partially sent, %d of %d bytes written: %w", written, total, err))
+}
+
+type syslogPushProviderExtraParams struct {
+	// Facility is authpriv by default
+	Facility string `json:"facility,omitempty" validate:"omitempty,oneof=kern user mail daemon auth syslog lpr news uucp cron authpriv ftp ntp security console clock local0 local1 local2 local3 local4 local5 local6 local7"`
+	AppName  string `json:"appName,omitempty" validate:"omitempty,printascii,max=48,excludes= "`
+	// SDID is ID of structured data element, in name@enterpriseNumber form
+	SDID string `json:"sdID,omitempty" validate:"omitempty,printascii,max=32,contains=@,excludesall= =]\""`
+	// Hostname overrides Host field of the alert
+	Hostname string `json:"hostname,omitempty" validate:"omitempty,hostname_rfc1123"`
+	// Payload is text by default, cef for ArcSight and leef for QRadar
+	Payload string `json:"payload,omitempty" validate:"omitempty,oneof=text cef leef"`
+}
@@ -0,0 +1,256 @@
+package pushsdk
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"rdpalert/embedded"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syslogMessage is a parsed RFC 5424 message with a single SD element
+type syslogMessage struct {
+	pri                                 int
+	timestamp, host, app, procID, msgID string
+	sdID                                string
+	sdParams                            map[string]string
+	msg                                 string
+}
+
+// parseSyslogMessage is a strict parser of what syslogPushContent renders, SD param values are unescaped
+func parseSyslogMessage(t *testing.T, raw string) *syslogMessage {
+	t.Helper()
+	m := &syslogMessage{sdParams: map[string]string{}}
+	head := strings.SplitN(raw, " ", 7)
+	if len(head) != 7 || !strings.HasPrefix(head[0], "<") || !strings.HasSuffix(head[0], ">1") {
+		t.Fatalf("malformed header: %q", raw)
+	}
+	pri, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(head[0], "<"), ">1"))
+	if err != nil {
+		t.Fatalf("malformed PRI: %q", head[0])
+	}
+	m.pri = pri
+	m.timestamp, m.host, m.app, m.procID, m.msgID = head[1], head[2], head[3], head[4], head[5]
+	rest := head[6]
+	if !strings.HasPrefix(rest, "[") {
+		t.Fatalf("missing SD element: %q", rest)
+	}
+	i := strings.IndexAny(rest, " ]")
+	m.sdID = rest[1:i]
+	rest = rest[i:]
+	for strings.HasPrefix(rest, " ") {
+		eq := strings.Index(rest, `="`)
+		name := rest[1:eq]
+		value := &strings.Builder{}
+		j := eq + 2
+		for ; rest[j] != '"'; j++ {
+			if rest[j] == '\\' {
+				j++
+			}
+			value.WriteByte(rest[j])
+		}
+		m.sdParams[name] = value.String()
+		rest = rest[j+1:]
+	}
+	if !strings.HasPrefix(rest, "] \ufeff") {
+		t.Fatalf("SD element is not closed or MSG has no BOM: %q", rest)
+	}
+	m.msg = strings.TrimPrefix(rest, "] \ufeff")
+	return m
+}
+
+// listenSyslog starts a collector on scheme and returns the server URL and the channel of received messages,
+// stream transports are de-framed by octet counting
+func listenSyslog(t *testing.T, scheme string, cert tls.Certificate) (string, <-chan string) {
+	t.Helper()
+	received := make(chan string, 4)
+	if scheme == "udp" {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = pc.Close() })
+		go func() {
+			buf := make([]byte, 65536)
+			for {
+				n, _, err := pc.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				received <- string(buf[:n])
+			}
+		}()
+		return "udp://" + pc.LocalAddr().String(), received
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheme == "tls" {
+		l = tls.NewListener(l, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				r := bufio.NewReader(conn)
+				for {
+					lenStr, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+					if err != nil {
+						received <- "bad frame length: " + lenStr
+						return
+					}
+					frame := make([]byte, n)
+					if _, err = io.ReadFull(r, frame); err != nil {
+						return
+					}
+					received <- string(frame)
+				}
+			}()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return scheme + "://localhost:" + port, received
+}
+
+func testSyslogAlert() *GeneralPushContent {
+	return &GeneralPushContent{
+		Title:      "RDP Login | Success",
+		ShortTitle: "alice from 10.0.0.8 into WIN-SRV01",
+		Severity:   SeverityWarning,
+		EventType:  EventRDPLoginSuccess,
+		OccurredAt: time.Date(2026, 3, 1, 8, 30, 0, 123456000, time.UTC),
+		Fields: []PushField{
+			{Name: FieldSourceIP, Value: "10.0.0.8"},
+			{Name: FieldUser, Value: `ali"ce]\x=1`},
+			{Name: FieldDomain, Value: "CORP"},
+			{Name: FieldHost, Value: "WIN-SRV01"},
+			{Name: FieldHostIPs, Value: "10.0.0.2,\n10.0.0.3\t"},
+		},
+	}
+}
+
+func sendTestSyslog(t *testing.T, prv *syslogPushProvider, received <-chan string) *syslogMessage {
+	t.Helper()
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(testSyslogAlert())
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	if _, err = prv.SendPushContent(context.Background(), spc); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	select {
+	case raw := <-received:
+		return parseSyslogMessage(t, raw)
+	case <-time.After(5 * time.Second):
+		t.Fatal("collector received nothing")
+		return nil
+	}
+}
+
+func TestSyslogTransports(t *testing.T) {
+	cert, caFile := newTestCertificate(t)
+	for _, scheme := range []string{"udp", "tcp", "tls"} {
+		t.Run(scheme, func(t *testing.T) {
+			serverURL, received := listenSyslog(t, scheme, cert)
+			prv := &syslogPushProvider{
+				ProviderCommon:    ProviderCommon{Retry: &RetryPolicy{MaxAttempts: 1}, HTTPClient: &HTTPClientConfig{CABundleFile: caFile}},
+				ProviderServerURL: serverURL,
+			}
+			m := sendTestSyslog(t, prv, received)
+			// authpriv.warning
+			if m.pri != 10*8+4 {
+				t.Errorf("PRI = %d, want 84", m.pri)
+			}
+			if m.timestamp != "2026-03-01T08:30:00.123456Z" || m.host != "WIN-SRV01" || m.app != defaultSyslogAppName ||
+				m.procID != strconv.Itoa(os.Getpid()) || m.msgID != EventRDPLoginSuccess {
+				t.Errorf("header = %s %s %s %s %s", m.timestamp, m.host, m.app, m.procID, m.msgID)
+			}
+			if m.sdID != defaultSyslogSDID {
+				t.Errorf("SD-ID = %q", m.sdID)
+			}
+			wantSD := map[string]string{"user": `ali"ce]\x=1`, "domain": "CORP", "srcIP": "10.0.0.8", "host": "WIN-SRV01", "hostIPs": "10.0.0.2,\n10.0.0.3\t"}
+			for k, v := range wantSD {
+				if m.sdParams[k] != v {
+					t.Errorf("SD param %s = %q, want %q", k, m.sdParams[k], v)
+				}
+			}
+			if m.msg != "RDP Login | Success: alice from 10.0.0.8 into WIN-SRV01" {
+				t.Errorf("MSG = %q", m.msg)
+			}
+		})
+	}
+}
+
+func TestSyslogCEFAndLEEF(t *testing.T) {
+	cases := []struct {
+		payload string
+		want    string
+	}{
+		{SyslogPayloadCEF, "CEF:0|kmahyyg|DumbRDPAlert|" + cefHeaderEscaper.Replace(embedded.CurVersionStr) + "|rdpLoginSuccess|RDP Login \\| Success|5|" +
+			`rt=1772353800123 src=10.0.0.8 duser=ali"ce]\\x\=1 dntdom=CORP dhost=WIN-SRV01 cs1=10.0.0.2,\n10.0.0.3` + "\t" +
+			` msg=alice from 10.0.0.8 into WIN-SRV01 cs1Label=Host IPs`},
+		{SyslogPayloadLEEF, "LEEF:1.0|kmahyyg|DumbRDPAlert|" + leefHeaderEscaper.Replace(embedded.CurVersionStr) + "|rdpLoginSuccess|" +
+			"devTime=1772353800123\tsev=5\tsrc=10.0.0.8\tusrName=" + `ali"ce]\x=1` + "\tdomain=CORP\tidentHostName=WIN-SRV01\thostIPs=10.0.0.2, 10.0.0.3 "},
+	}
+	for _, c := range cases {
+		t.Run(c.payload, func(t *testing.T) {
+			serverURL, received := listenSyslog(t, "udp", tls.Certificate{})
+			prv := &syslogPushProvider{
+				ProviderCommon:    ProviderCommon{Retry: &RetryPolicy{MaxAttempts: 1}},
+				ProviderServerURL: serverURL,
+				ExtraParams:       &syslogPushProviderExtraParams{Payload: c.payload, Facility: "security"},
+			}
+			m := sendTestSyslog(t, prv, received)
+			if m.pri != 13*8+4 {
+				t.Errorf("PRI = %d, want 108", m.pri)
+			}
+			if m.msg != c.want {
+				t.Errorf("MSG =\n%q\nwant\n%q", m.msg, c.want)
+			}
+		})
+	}
+}
+
+func TestSyslogWriteError(t *testing.T) {
+	writeErr := errors.New("connection reset by peer")
+	cases := []struct {
+		scheme    string
+		written   int
+		retryable bool
+	}{
+		{"udp", 0, true},
+		{"tcp", 0, true},
+		{"tcp", 10, false},
+		{"tls", 0, false},
+	}
+	policy := &RetryPolicy{RetryableNetErrors: []string{NetErrAll}}
+	for _, c := range cases {
+		err := syslogWriteError(c.scheme, c.written, 100, &net.OpError{Op: "write", Net: "tcp", Err: writeErr})
+		if got := policy.IsRetryable(err); got != c.retryable {
+			t.Errorf("%s with %d bytes written: retryable = %v, want %v", c.scheme, c.written, got, c.retryable)
+		}
+	}
+	if syslogWriteError("tcp", 100, 100, nil) != nil {
+		t.Error("successful write reported as error")
+	}
+}
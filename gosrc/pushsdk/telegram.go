@@ -0,0 +1,252 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTelegramAPIBaseURL = "https://api.telegram.org"
+
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+	TelegramParseModeHTML       = "HTML"
+)
+
+// telegramMarkdownV2Escaper escapes every character reserved by MarkdownV2,
+// check: https://core.telegram.org/bots/api#markdownv2-style
+var telegramMarkdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// telegramPushContent is an instance of sendMessage request, ChatID and MessageThreadID are set per chat
+type telegramPushContent struct {
+	ChatID              string                      `json:"chat_id"`
+	MessageThreadID     int64                       `json:"message_thread_id,omitempty"`
+	Text                string                      `json:"text" validate:"required"`
+	ParseMode           string                      `json:"parse_mode,omitempty"`
+	DisableNotification bool                        `json:"disable_notification,omitempty"`
+	LinkPreviewOptions  *telegramLinkPreviewOptions `json:"link_preview_options,omitempty"`
+
+	// delivered are chats reached by previous attempt, they are skipped
+	delivered []string
+	// provider info
+	providerName PushProvider
+}
+
+type telegramLinkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+func (tpc *telegramPushContent) Init() {
+	tpc.SetPushProvider()
+}
+
+func (tpc *telegramPushContent) Provider() PushProvider {
+	return tpc.providerName
+}
+
+func (tpc *telegramPushContent) SetPushProvider() {
+	tpc.providerName = Telegram
+}
+
+func (tpc *telegramPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*telegramPushProviderExtraParams)
+	tpc.ParseMode = d1.ParseMode
+	tpc.DisableNotification = d1.DisableNotification
+	if d1.DisableLinkPreview {
+		tpc.LinkPreviewOptions = &telegramLinkPreviewOptions{IsDisabled: true}
+	}
+}
+
+// FromGeneral escapes every field according to parse mode, as they may come from attacker controlled login info
+func (tpc *telegramPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	switch tpc.ParseMode {
+	case TelegramParseModeMarkdownV2:
+		tpc.Text = fmt.Sprintf("*%s*\n%s", escapeTelegramMarkdownV2(g.Title), escapeTelegramMarkdownV2(g.Description))
+	case TelegramParseModeHTML:
+		tpc.Text = fmt.Sprintf("<b>%s</b>\n%s", html.EscapeString(g.Title), html.EscapeString(g.Description))
+	default:
+		tpc.Text = g.Title + "\n" + g.Description
+	}
+	tpc.delivered = g.DeliveredTargets()
+	return tpc, nil
+}
+
+func (tpc *telegramPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(tpc)
+}
+
+// escapeTelegramMarkdownV2 makes s a literal text in MarkdownV2 message
+func escapeTelegramMarkdownV2(s string) string {
+	return telegramMarkdownV2Escaper.Replace(s)
+}
+
+func init() {
+	MustRegisterProvider(Telegram, func() PushProviderImpl { return &telegramPushProvider{} })
+}
+
+type telegramPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is Bot API base URL, https://api.telegram.org by default
+	ProviderServerURL string                           `json:"serverURL,omitempty" validate:"omitempty,url"`
+	ExtraParams       *telegramPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (t telegramPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(t)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(t.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (t telegramPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	tpc := &telegramPushContent{}
+	tpc.Init()
+	tpc.AcceptExtParamSettings(t.ExtraParams)
+	return tpc.FromGeneral(g)
+}
+
+// SendPushContent sends message to every configured chat, failure of one chat doesn't stop the others,
+// *PartialDeliveryError tells which chats are reached if some of them failed
+func (t telegramPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*telegramPushContent)
+	baseURL := t.ProviderServerURL
+	if baseURL == "" {
+		baseURL = defaultTelegramAPIBaseURL
+	}
+	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(baseURL, "/"), t.ExtraParams.BotToken)
+	sent := make([]string, 0, len(t.ExtraParams.Chats))
+	delivered := slices.Clone(pData.delivered)
+	errs := make([]error, 0)
+	for _, chat := range t.ExtraParams.Chats {
+		if slices.Contains(pData.delivered, chat.String()) {
+			continue
+		}
+		msg := *pData
+		msg.ChatID = chat.ChatID
+		msg.MessageThreadID = chat.MessageThreadID
+		body, err := msg.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		respData, _, err := t.SendRequest(ctx, Telegram, &HTTPRequest{
+			Method:     http.MethodPost,
+			URL:        apiURL,
+			Header:     http.Header{"Content-Type": {postJSONContentType}},
+			Body:       body,
+			RetryAfter: parseTelegramRetryAfter,
+			Secrets:    []string{t.ExtraParams.BotToken},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ChatID, parseTelegramError(err)))
+			continue
+		}
+		tpr := &telegramPushResponse{}
+		err = json.Unmarshal(respData, tpr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ChatID, err))
+			continue
+		}
+		if !tpr.OK {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.ChatID, newTelegramError(tpr.ErrorCode, tpr.Description, nil)))
+			continue
+		}
+		sent = append(sent, fmt.Sprintf("%s#%d", chat.ChatID, tpr.Result.MessageID))
+		delivered = append(delivered, chat.String())
+	}
+	if len(errs) != 0 {
+		if len(delivered) != 0 {
+			return nil, &PartialDeliveryError{Delivered: delivered, Err: errors.Join(errs...)}
+		}
+		return nil, errors.Join(errs...)
+	}
+	return &PushResponse{
+		Code:      http.StatusOK,
+		Message:   "Sent MessageIDs: " + strings.Join(sent, ", "),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+// parseTelegramRetryAfter reads parameters.retry_after from error response of Bot API
+func parseTelegramRetryAfter(_ http.Header, body []byte) time.Duration {
+	tpr := &telegramPushResponse{}
+	if json.Unmarshal(body, tpr) != nil {
+		return 0
+	}
+	return time.Duration(tpr.Parameters.RetryAfter) * time.Second
+}
+
+// parseTelegramError turns error response of Bot API into *ProviderError
+func parseTelegramError(err error) error {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	tpr := &telegramPushResponse{}
+	if json.Unmarshal(statusErr.Body, tpr) != nil || tpr.ErrorCode == 0 {
+		return newTelegramError(statusErr.StatusCode, truncateString(strings.TrimSpace(string(statusErr.Body)), 200), err)
+	}
+	return newTelegramError(tpr.ErrorCode, tpr.Description, err)
+}
+
+// newTelegramError classifies error_code, which follows HTTP status, chat unreachable by the bot is told by description,
+// e.g. "Bad Request: chat not found" or "Forbidden: bot was blocked by the user"
+func newTelegramError(code int, description string, err error) *ProviderError {
+	kind := errorKindByStatus(code)
+	for _, v := range []string{"chat not found", "bot was blocked", "bot was kicked", "user is deactivated", "not a member"} {
+		if strings.Contains(description, v) {
+			kind = ErrInvalidDeviceKey
+			break
+		}
+	}
+	return &ProviderError{Provider: Telegram, Kind: kind, Code: code, Message: description, Err: err}
+}
+
+type telegramPushResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code,omitempty"`
+	Description string `json:"description,omitempty"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+	Parameters struct {
+		RetryAfter int `json:"retry_after,omitempty"`
+	} `json:"parameters"`
+}
+
+type telegramPushProviderExtraParams struct {
+	BotToken string         `json:"botToken" validate:"required"`
+	Chats    []telegramChat `json:"chats" validate:"required,min=1,dive"`
+	// ParseMode is plain text if empty
+	ParseMode           string `json:"parseMode,omitempty" validate:"omitempty,oneof=MarkdownV2 HTML"`
+	DisableNotification bool   `json:"disableNotification,omitempty"`
+	DisableLinkPreview  bool   `json:"disableLinkPreview,omitempty"`
+}
+
+type telegramChat struct {
+	// ChatID is numeric ID or @channelusername
+	ChatID string `json:"chatID" validate:"required"`
+	// MessageThreadID sends message to a topic of forum supergroup
+	MessageThreadID int64 `json:"threadID,omitempty" validate:"omitempty,gt=0"`
+}
+
+// String identifies chat and topic in PartialDeliveryError.Delivered
+func (c telegramChat) String() string {
+	if c.MessageThreadID != 0 {
+		return fmt.Sprintf("%s/%d", c.ChatID, c.MessageThreadID)
+	}
+	return c.ChatID
+}
@@ -0,0 +1,143 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTelegramAPI records chat_id of every sendMessage, chats in failing get a 500
+type fakeTelegramAPI struct {
+	mu      sync.Mutex
+	failing map[string]bool
+	got     []string
+}
+
+func (f *fakeTelegramAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	msg := &telegramPushContent{}
+	_ = json.NewDecoder(r.Body).Decode(msg)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.got = append(f.got, msg.ChatID)
+	if f.failing[msg.ChatID] {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"Internal Server Error"}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+}
+
+func (f *fakeTelegramAPI) received() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := f.got
+	f.got = nil
+	return res
+}
+
+func TestTelegramReplayOnlyFailedChats(t *testing.T) {
+	api := &fakeTelegramAPI{failing: map[string]bool{"-1002": true}}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	conf := &PushConfig{}
+	err := json.Unmarshal([]byte(`{
+		"retry": {"maxAttempts": 1},
+		"pushMethods": {"telegram": {"serverURL": "`+srv.URL+`", "extParams": {
+			"botToken": "123456:ABC",
+			"chats": [{"chatID": "-1001"}, {"chatID": "-1002"}, {"chatID": "@rdpalert"}]
+		}}}
+	}`), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPusher(conf)
+	if err != nil {
+		t.Fatalf("new pusher: %v", err)
+	}
+	outbox, err := NewOutbox(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.AttachOutbox(outbox)
+	p.StageGeneralPushContent(&GeneralPushContent{Title: "RDP Login - Success", Description: "alice from 10.0.0.8"})
+
+	report, err := p.SendPush(context.Background())
+	var partialErr *PartialDeliveryError
+	if err == nil || !errors.As(report.Failed()[0].Err, &partialErr) {
+		t.Fatalf("send push: want *PartialDeliveryError, got %v", err)
+	}
+	if !slices.Equal(partialErr.Delivered, []string{"-1001", "@rdpalert"}) {
+		t.Errorf("delivered = %v", partialErr.Delivered)
+	}
+	api.received()
+
+	entries, err := outbox.List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("outbox entries = %d, %v", len(entries), err)
+	}
+	if got := entries[0].Content.Delivered[Telegram]; !slices.Equal(got, []string{"-1001", "@rdpalert"}) {
+		t.Errorf("spooled delivered targets = %v", got)
+	}
+
+	api.failing = nil
+	flushed, err := p.FlushOutbox(context.Background())
+	if err != nil || flushed != 1 {
+		t.Fatalf("flush outbox: %d, %v", flushed, err)
+	}
+	if got := api.received(); !slices.Equal(got, []string{"-1002"}) {
+		t.Errorf("replay sent to %v, want only the failed chat", got)
+	}
+}
+
+func TestEscapeTelegramMarkdownV2(t *testing.T) {
+	// every reserved character gets a backslash, including backslash itself
+	for _, c := range strings.Split("_*[]()~`>#+-=|{}.!\\", "") {
+		if got := escapeTelegramMarkdownV2(c); got != `\`+c {
+			t.Errorf("escape %q = %q, want %q", c, got, `\`+c)
+		}
+	}
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"alice", "alice"},
+		{`CORP\alice`, `CORP\\alice`},
+		{"10.0.0.8", `10\.0\.0\.8`},
+		{"[click](https://evil.example)", `\[click\]\(https://evil\.example\)`},
+		{"*bold* _it_ ~s~ `code` ||spoiler||", "\\*bold\\* \\_it\\_ \\~s\\~ \\`code\\` \\|\\|spoiler\\|\\|"},
+		{"ДОМЕН\\пользователь!", `ДОМЕН\\пользователь\!`},
+	}
+	for _, c := range cases {
+		if got := escapeTelegramMarkdownV2(c.in); got != c.want {
+			t.Errorf("escape %q = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTelegramTextByParseMode(t *testing.T) {
+	g := &GeneralPushContent{Title: "RDP Login - Success", Description: "User: CORP\\<b>alice</b> & [x](y)_1.0"}
+	cases := []struct {
+		parseMode string
+		want      string
+	}{
+		{"", "RDP Login - Success\nUser: CORP\\<b>alice</b> & [x](y)_1.0"},
+		{TelegramParseModeMarkdownV2, "*RDP Login \\- Success*\nUser: CORP\\\\<b\\>alice</b\\> & \\[x\\]\\(y\\)\\_1\\.0"},
+		{TelegramParseModeHTML, "<b>RDP Login - Success</b>\nUser: CORP\\&lt;b&gt;alice&lt;/b&gt; &amp; [x](y)_1.0"},
+	}
+	for _, c := range cases {
+		prv := telegramPushProvider{ExtraParams: &telegramPushProviderExtraParams{ParseMode: c.parseMode}}
+		spc, err := prv.TransformToSpecificPushContent(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := spc.(*telegramPushContent).Text; got != c.want {
+			t.Errorf("parse mode %q: text = %q\nwant %q", c.parseMode, got, c.want)
+		}
+	}
+}
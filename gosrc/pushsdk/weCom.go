@@ -0,0 +1,181 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const (
+	WeComFormatMarkdown     = "markdown"
+	WeComFormatTemplateCard = "template_card"
+
+	// weComErrCodeFreqOutOfLimit is returned when robot sends more than 20 messages per minute
+	weComErrCodeFreqOutOfLimit = 45009
+	// weComErrCodeInvalidWebhook is returned when webhook key is wrong or robot is removed
+	weComErrCodeInvalidWebhook = 93000
+)
+
+// weComErrCodeKinds classifies errcode of robot response
+var weComErrCodeKinds = map[int]error{
+	weComErrCodeFreqOutOfLimit: ErrRateLimited,
+	weComErrCodeInvalidWebhook: ErrInvalidDeviceKey,
+}
+
+// weComPushContent is a group robot message, check: https://developer.work.weixin.qq.com/document/path/91770
+type weComPushContent struct {
+	MsgType      string             `json:"msgtype" validate:"required"`
+	Markdown     *weComMarkdown     `json:"markdown,omitempty"`
+	TemplateCard *weComTemplateCard `json:"template_card,omitempty"`
+
+	actionURL string
+	// provider info
+	providerName PushProvider
+}
+
+type weComMarkdown struct {
+	Content string `json:"content"`
+}
+
+// weComTemplateCard is a text_notice card, CardAction is mandatory for this type
+type weComTemplateCard struct {
+	CardType              string                  `json:"card_type"`
+	Source                weComCardSource         `json:"source"`
+	MainTitle             weComCardTitle          `json:"main_title"`
+	HorizontalContentList []weComCardHorizontalKV `json:"horizontal_content_list,omitempty"`
+	CardAction            weComCardAction         `json:"card_action"`
+}
+
+type weComCardSource struct {
+	Desc string `json:"desc"`
+}
+
+type weComCardTitle struct {
+	Title string `json:"title"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+type weComCardHorizontalKV struct {
+	KeyName string `json:"keyname"`
+	Value   string `json:"value"`
+}
+
+type weComCardAction struct {
+	Type int    `json:"type"`
+	URL  string `json:"url"`
+}
+
+func (wpc *weComPushContent) Init() {
+	wpc.MsgType = WeComFormatMarkdown
+	wpc.SetPushProvider()
+}
+
+func (wpc *weComPushContent) Provider() PushProvider {
+	return wpc.providerName
+}
+
+func (wpc *weComPushContent) SetPushProvider() {
+	wpc.providerName = WeCom
+}
+
+func (wpc *weComPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*weComPushProviderExtraParams)
+	if d1 == nil {
+		return
+	}
+	if d1.Format != "" {
+		wpc.MsgType = d1.Format
+	}
+	wpc.actionURL = d1.ActionURL
+}
+
+func (wpc *weComPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	switch wpc.MsgType {
+	case WeComFormatTemplateCard:
+		card := &weComTemplateCard{
+			CardType:   "text_notice",
+			Source:     weComCardSource{Desc: "RDPAlert"},
+			MainTitle:  weComCardTitle{Title: truncateString(g.Title, 26), Desc: truncateString(g.ShortTitle, 30)},
+			CardAction: weComCardAction{Type: 1, URL: wpc.actionURL},
+		}
+		for _, v := range g.Fields {
+			// card holds at most 6 rows
+			if len(card.HorizontalContentList) == 6 {
+				break
+			}
+			card.HorizontalContentList = append(card.HorizontalContentList, weComCardHorizontalKV{KeyName: v.Name, Value: v.Value})
+		}
+		wpc.TemplateCard = card
+	default:
+		wpc.Markdown = &weComMarkdown{Content: "### " + g.Title + "\n" + robotMarkdownBody(g)}
+	}
+	return wpc, nil
+}
+
+func (wpc *weComPushContent) ToBytes() ([]byte, error) {
+	return json.Marshal(wpc)
+}
+
+func init() {
+	MustRegisterProvider(WeCom, func() PushProviderImpl { return &weComPushProvider{} })
+}
+
+type weComPushProvider struct {
+	ProviderCommon
+	// ProviderServerURL is robot webhook URL, https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=xxx
+	ProviderServerURL string                        `json:"serverURL" validate:"url,required"`
+	ExtraParams       *weComPushProviderExtraParams `json:"extParams" validate:"omitempty"`
+}
+
+func (w weComPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(w)
+	if err1 != nil {
+		return err1
+	}
+	if w.ExtraParams == nil {
+		return nil
+	}
+	err2 := verifier.Struct(w.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+func (w weComPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	wpc := &weComPushContent{}
+	wpc.Init()
+	wpc.AcceptExtParamSettings(w.ExtraParams)
+	return wpc.FromGeneral(g)
+}
+
+func (w weComPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*weComPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, 1)
+	if u, err := url.Parse(w.ProviderServerURL); err == nil && u.RawQuery != "" {
+		secrets = append(secrets, u.RawQuery)
+	}
+	respData, _, err := w.SendRequest(ctx, WeCom, &HTTPRequest{
+		Method:  http.MethodPost,
+		URL:     w.ProviderServerURL,
+		Header:  http.Header{"Content-Type": {postJSONContentType}},
+		Body:    body,
+		Secrets: secrets,
+	})
+	if err != nil {
+		return nil, parseStatusError(WeCom, err)
+	}
+	return parseRobotResponse(WeCom, respData, weComErrCodeKinds)
+}
+
+type weComPushProviderExtraParams struct {
+	// Format is markdown by default
+	Format string `json:"format,omitempty" validate:"omitempty,oneof=markdown template_card"`
+	// ActionURL is opened when template card is clicked, required by template_card
+	ActionURL string `json:"actionURL,omitempty" validate:"required_if=Format template_card,omitempty,url"`
+}
@@ -0,0 +1,268 @@
+package pushsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var (
+	ErrWebhookConditionNotMet = errors.New("webhook response does not meet success condition")
+
+	webhookTemplateFuncs = template.FuncMap{
+		// json renders v as JSON value, use it to embed strings into JSON body safely
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join": strings.Join,
+		"now": func() string {
+			return time.Now().Format(time.RFC3339)
+		},
+	}
+)
+
+const defaultWebhookBodyTemplate = `{"title":{{json .Title}},"short_title":{{json .ShortTitle}},"description":{{json .Description}},"tags_or_groups":{{json .TagsOrGroups}},"ext_params":{{json .ExtParams}}}`
+
+type webhookPushContent struct {
+	Headers http.Header
+	Body    []byte
+
+	bodyTmpl    *template.Template
+	headerTmpls map[string]*template.Template
+	// provider info
+	providerName PushProvider
+}
+
+func (wpc *webhookPushContent) Init() {
+	wpc.Headers = http.Header{}
+	wpc.SetPushProvider()
+}
+
+func (wpc *webhookPushContent) Provider() PushProvider {
+	return wpc.providerName
+}
+
+func (wpc *webhookPushContent) SetPushProvider() {
+	wpc.providerName = Webhook
+}
+
+func (wpc *webhookPushContent) AcceptExtParamSettings(d any) {
+	d1 := d.(*webhookPushProviderExtraParams)
+	wpc.bodyTmpl = d1.bodyTmpl
+	wpc.headerTmpls = d1.headerTmpls
+	wpc.Headers.Set("Content-Type", d1.ContentType)
+}
+
+// FromGeneral renders body and header templates with g
+func (wpc *webhookPushContent) FromGeneral(g *GeneralPushContent) (PushContent, error) {
+	buf := &bytes.Buffer{}
+	err := wpc.bodyTmpl.Execute(buf, g)
+	if err != nil {
+		return nil, fmt.Errorf("render webhook body: %w", err)
+	}
+	wpc.Body = buf.Bytes()
+	for k, v := range wpc.headerTmpls {
+		buf = &bytes.Buffer{}
+		err = v.Execute(buf, g)
+		if err != nil {
+			return nil, fmt.Errorf("render webhook header %s: %w", k, err)
+		}
+		wpc.Headers.Set(k, buf.String())
+	}
+	return wpc, nil
+}
+
+func (wpc *webhookPushContent) ToBytes() ([]byte, error) {
+	return wpc.Body, nil
+}
+
+func init() {
+	MustRegisterProvider(Webhook, func() PushProviderImpl { return &webhookPushProvider{} })
+}
+
+type webhookPushProvider struct {
+	ProviderCommon
+	ProviderServerURL string                          `json:"serverURL" validate:"url,required"`
+	ExtraParams       *webhookPushProviderExtraParams `json:"extParams" validate:"required"`
+}
+
+func (w *webhookPushProvider) VerifyConfig() error {
+	err1 := verifier.Struct(w)
+	if err1 != nil {
+		return err1
+	}
+	err2 := verifier.Struct(w.ExtraParams)
+	if err2 != nil {
+		return err2
+	}
+	// parse templates now, so mistakes are found before any alert
+	return w.ExtraParams.compile()
+}
+
+func (w *webhookPushProvider) TransformToSpecificPushContent(g *GeneralPushContent) (PushContent, error) {
+	wpc := &webhookPushContent{}
+	wpc.Init()
+	wpc.AcceptExtParamSettings(w.ExtraParams)
+	return wpc.FromGeneral(g)
+}
+
+func (w *webhookPushProvider) SendPushContent(ctx context.Context, p PushContent) (*PushResponse, error) {
+	pData := p.(*webhookPushContent)
+	body, err := pData.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	req := &HTTPRequest{
+		Method: w.ExtraParams.Method,
+		URL:    w.ProviderServerURL,
+		Header: pData.Headers,
+		Body:   body,
+	}
+	if req.Method == http.MethodGet {
+		req.Body = nil
+	}
+	if len(w.ExtraParams.SuccessStatusCodes) != 0 {
+		req.AcceptStatus = func(code int) bool {
+			return slices.Contains(w.ExtraParams.SuccessStatusCodes, code)
+		}
+	}
+	respData, statusCode, err := w.SendRequest(ctx, Webhook, req)
+	if err != nil {
+		return nil, err
+	}
+	if w.ExtraParams.SuccessField != nil {
+		err = w.ExtraParams.SuccessField.check(respData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &PushResponse{
+		Code:      statusCode,
+		Message:   truncateString(string(respData), 256),
+		Timestamp: time.Now().Unix(),
+	}, nil
+}
+
+type webhookPushProviderExtraParams struct {
+	// Method is POST by default
+	Method string `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT PATCH"`
+	// Headers values are text/template, rendered with GeneralPushContent
+	Headers map[string]string `json:"headers,omitempty" validate:"omitempty"`
+	// ContentType is "application/json; charset=utf-8" by default
+	ContentType string `json:"contentType,omitempty" validate:"omitempty"`
+	// BodyTemplate is text/template rendered with GeneralPushContent, whole content in JSON by default
+	BodyTemplate string `json:"bodyTemplate,omitempty" validate:"omitempty"`
+	// SuccessStatusCodes overrides default 2xx check
+	SuccessStatusCodes []int                       `json:"successStatusCodes,omitempty" validate:"omitempty,dive,gte=100,lte=599"`
+	SuccessField       *webhookResponseSuccessRule `json:"successField,omitempty" validate:"omitempty"`
+
+	bodyTmpl    *template.Template
+	headerTmpls map[string]*template.Template
+}
+
+func (wep *webhookPushProviderExtraParams) compile() error {
+	if wep.Method == "" {
+		wep.Method = http.MethodPost
+	}
+	if wep.ContentType == "" {
+		wep.ContentType = postJSONContentType
+	}
+	if wep.BodyTemplate == "" {
+		wep.BodyTemplate = defaultWebhookBodyTemplate
+	}
+	var err error
+	wep.bodyTmpl, err = template.New("body").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(wep.BodyTemplate)
+	if err != nil {
+		return fmt.Errorf("parse webhook body template: %w", err)
+	}
+	wep.headerTmpls = make(map[string]*template.Template, len(wep.Headers))
+	for k, v := range wep.Headers {
+		wep.headerTmpls[k], err = template.New(k).Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(v)
+		if err != nil {
+			return fmt.Errorf("parse webhook header template %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// webhookResponseSuccessRule checks a field of JSON response
+type webhookResponseSuccessRule struct {
+	// Path is dot separated, array index could be written as "items.0" or "items[0]", leading "$." is optional
+	Path string `json:"path" validate:"required"`
+	// Equals compares with field in string form, if empty, field must be present and not false, 0, null or ""
+	Equals string `json:"equals,omitempty"`
+}
+
+func (wrsr *webhookResponseSuccessRule) check(respData []byte) error {
+	var doc any
+	err := json.Unmarshal(respData, &doc)
+	if err != nil {
+		return fmt.Errorf("%w: response is not JSON: %s", ErrWebhookConditionNotMet, err.Error())
+	}
+	v, found := lookupJSONPath(doc, wrsr.Path)
+	if !found {
+		return fmt.Errorf("%w: %s not found", ErrWebhookConditionNotMet, wrsr.Path)
+	}
+	if wrsr.Equals != "" {
+		if fmt.Sprint(v) != wrsr.Equals {
+			return fmt.Errorf("%w: %s is %v, expect %s", ErrWebhookConditionNotMet, wrsr.Path, v, wrsr.Equals)
+		}
+		return nil
+	}
+	switch v1 := v.(type) {
+	case nil:
+	case bool:
+		if v1 {
+			return nil
+		}
+	case float64:
+		if v1 != 0 {
+			return nil
+		}
+	case string:
+		if v1 != "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: %s is %v", ErrWebhookConditionNotMet, wrsr.Path, v)
+}
+
+// lookupJSONPath walks decoded JSON doc by a simplified JSONPath
+func lookupJSONPath(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.ReplaceAll(strings.ReplaceAll(path, "[", "."), "]", "")
+	cur := doc
+	if path == "" {
+		return cur, true
+	}
+	for _, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, exists := node[seg]
+			if !exists {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			cur = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
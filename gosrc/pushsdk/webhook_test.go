@@ -0,0 +1,165 @@
+package pushsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// webhookCapture records the last request and replies status with body
+type webhookCapture struct {
+	status int
+	body   string
+
+	method string
+	header http.Header
+	got    []byte
+}
+
+func (c *webhookCapture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.method = r.Method
+	c.header = r.Header
+	c.got, _ = io.ReadAll(r.Body)
+	w.WriteHeader(c.status)
+	_, _ = w.Write([]byte(c.body))
+}
+
+func sendWebhook(t *testing.T, url string, extParams string, g *GeneralPushContent) (*PushResponse, error) {
+	t.Helper()
+	prv := &webhookPushProvider{}
+	err := json.Unmarshal([]byte(`{"serverURL": "`+url+`", "extParams": `+extParams+`}`), prv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prv.ProviderCommon = noRetry()
+	if err := prv.VerifyConfig(); err != nil {
+		t.Fatalf("verify config: %v", err)
+	}
+	spc, err := prv.TransformToSpecificPushContent(g)
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	return prv.SendPushContent(context.Background(), spc)
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	c := &webhookCapture{status: http.StatusOK, body: `{"ok":true}`}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	g := &GeneralPushContent{
+		Title:        "RDP Login - Success",
+		ShortTitle:   "alice from 10.0.0.8",
+		Description:  "User: CORP\\alice\n\"quoted\" <tag>",
+		TagsOrGroups: []string{"rdp", "corp"},
+		ExtParams:    map[string]any{"copyDest": "HOST-01"},
+	}
+
+	_, err := sendWebhook(t, srv.URL, `{
+		"method": "PUT",
+		"contentType": "application/vnd.alert+json",
+		"headers": {"X-Alert-Title": "{{.Title}}", "X-Alert-Host": "{{index .ExtParams \"copyDest\"}}"},
+		"bodyTemplate": "{\"text\":{{json .Description}},\"tags\":{{json (join .TagsOrGroups \",\")}},\"host\":{{json .ExtParams.copyDest}}}"
+	}`, g)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if c.method != http.MethodPut {
+		t.Errorf("method = %s", c.method)
+	}
+	if c.header.Get("Content-Type") != "application/vnd.alert+json" || c.header.Get("X-Alert-Title") != g.Title || c.header.Get("X-Alert-Host") != "HOST-01" {
+		t.Errorf("headers = %v", c.header)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(c.got, &got); err != nil {
+		t.Fatalf("body is not JSON: %v, %s", err, c.got)
+	}
+	want := map[string]any{"text": g.Description, "tags": "rdp,corp", "host": "HOST-01"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %v, want %v", got, want)
+	}
+
+	// default template sends the whole content as JSON
+	_, err = sendWebhook(t, srv.URL, `{}`, g)
+	if err != nil {
+		t.Fatalf("send with default template: %v", err)
+	}
+	got = nil
+	if err := json.Unmarshal(c.got, &got); err != nil {
+		t.Fatalf("default body is not JSON: %v, %s", err, c.got)
+	}
+	if c.method != http.MethodPost || c.header.Get("Content-Type") != postJSONContentType || got["title"] != g.Title || got["description"] != g.Description {
+		t.Errorf("default request %s %s: %v", c.method, c.header.Get("Content-Type"), got)
+	}
+}
+
+func TestWebhookSuccessStatusCodes(t *testing.T) {
+	cases := []struct {
+		status    int
+		extParams string
+		ok        bool
+	}{
+		{http.StatusOK, `{}`, true},
+		{http.StatusNoContent, `{}`, true},
+		{http.StatusFound, `{}`, false},
+		{http.StatusNoContent, `{"successStatusCodes": [204]}`, true},
+		{http.StatusOK, `{"successStatusCodes": [204]}`, false},
+		{http.StatusConflict, `{"successStatusCodes": [200, 409]}`, true},
+	}
+	g := &GeneralPushContent{Title: "RDP Login - Success"}
+	for _, c := range cases {
+		srv := httptest.NewServer(&webhookCapture{status: c.status})
+		resp, err := sendWebhook(t, srv.URL, c.extParams, g)
+		srv.Close()
+		if (err == nil) != c.ok {
+			t.Errorf("status %d with %s: error = %v, want ok %v", c.status, c.extParams, err, c.ok)
+			continue
+		}
+		var statusErr *HTTPStatusError
+		if err != nil && (!errors.As(err, &statusErr) || statusErr.StatusCode != c.status) {
+			t.Errorf("status %d with %s: want *HTTPStatusError, got %v", c.status, c.extParams, err)
+		}
+		if err == nil && resp.Code != c.status {
+			t.Errorf("status %d with %s: push response code %d", c.status, c.extParams, resp.Code)
+		}
+	}
+}
+
+func TestWebhookSuccessField(t *testing.T) {
+	cases := []struct {
+		body string
+		rule string
+		ok   bool
+	}{
+		{`{"ok":true}`, `{"path":"ok"}`, true},
+		{`{"ok":false}`, `{"path":"ok"}`, false},
+		{`{"ok":true}`, `{"path":"$.ok"}`, true},
+		{`{"code":0}`, `{"path":"code"}`, false},
+		{`{"code":0}`, `{"path":"code","equals":"0"}`, true},
+		{`{"code":40001}`, `{"path":"code","equals":"0"}`, false},
+		{`{"data":{"status":"sent"}}`, `{"path":"data.status","equals":"sent"}`, true},
+		{`{"data":{"status":""}}`, `{"path":"data.status"}`, false},
+		{`{"data":{"status":null}}`, `{"path":"data.status"}`, false},
+		{`{"items":[{"id":"a"},{"id":"b"}]}`, `{"path":"items[1].id","equals":"b"}`, true},
+		{`{"items":[{"id":"a"}]}`, `{"path":"items.1.id"}`, false},
+		{`{"items":[]}`, `{"path":"items"}`, true},
+		{`{"other":true}`, `{"path":"ok"}`, false},
+		{`<html>ok</html>`, `{"path":"ok"}`, false},
+	}
+	g := &GeneralPushContent{Title: "RDP Login - Success"}
+	for _, c := range cases {
+		srv := httptest.NewServer(&webhookCapture{status: http.StatusOK, body: c.body})
+		_, err := sendWebhook(t, srv.URL, `{"successField": `+c.rule+`}`, g)
+		srv.Close()
+		if (err == nil) != c.ok {
+			t.Errorf("%s with %s: error = %v, want ok %v", c.body, c.rule, err, c.ok)
+		}
+		if err != nil && !errors.Is(err, ErrWebhookConditionNotMet) {
+			t.Errorf("%s with %s: want ErrWebhookConditionNotMet, got %v", c.body, c.rule, err)
+		}
+	}
+}